@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"seeft-slack-notification/internal/fakeslack"
+)
+
+// 開発用のSlack API互換サーバー
+// バックエンドの SLACK_API_URL を http://localhost:9090/api/ に向けると、
+// 実際のワークスペース無しで通知の送信内容を確認できる
+//
+//	GET    /_calls               受け付けた呼び出しの一覧（?method=chat.postMessage で絞り込み）
+//	DELETE /_calls               記録のクリア
+//
+// Sign in with Slack (OIDC) のスタブも兼ねる。バックエンドの OIDC_ISSUER を
+// http://localhost:9090 に向けると、FAKE_SLACK_USERS のユーザーでログインできる
+// （docker compose ではブラウザから見たURLを FAKE_SLACK_PUBLIC_URL で指定する）
+func main() {
+	port := os.Getenv("FAKE_SLACK_PORT")
+	if port == "" {
+		port = "9090"
+	}
+
+	// FAKE_SLACK_USERS="U1234567890:山田太郎,U0987654321:佐藤花子"
+	members := parseMembers(os.Getenv("FAKE_SLACK_USERS"))
+
+	server, err := fakeslack.NewServer(members, os.Getenv("FAKE_SLACK_PUBLIC_URL"))
+	if err != nil {
+		log.Fatalf("Failed to create fake slack server: %v", err)
+	}
+
+	addr := fmt.Sprintf(":%s", port)
+	log.Printf("Fake Slack API listening on %s (%d members)", addr, len(members))
+	if err := http.ListenAndServe(addr, server.Handler()); err != nil {
+		log.Fatalf("Failed to start fake slack server: %v", err)
+	}
+}
+
+// parseMembers "ID:名前" のカンマ区切りを users.list 用のメンバーに変換する
+func parseMembers(value string) []fakeslack.Member {
+	members := []fakeslack.Member{}
+	for _, part := range strings.Split(value, ",") {
+		id, name, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok || id == "" {
+			continue
+		}
+		members = append(members, fakeslack.Member{ID: id, Name: name, RealName: name})
+	}
+	return members
+}
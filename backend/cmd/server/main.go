@@ -1,12 +1,21 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	_ "time/tzdata" // コンテナにタイムゾーン情報が無くても EVENT_TIMEZONE を読めるようにする
 
 	"seeft-slack-notification/internal/config"
 	"seeft-slack-notification/internal/database"
 	"seeft-slack-notification/internal/handler"
+	"seeft-slack-notification/internal/model"
 	"seeft-slack-notification/internal/repository"
 	"seeft-slack-notification/internal/service"
 
@@ -22,23 +31,41 @@ func main() {
 	}
 
 	// データベース接続
+	// ※ Close はシャットダウン処理の最後で明示的に呼ぶ
 	db, err := database.NewDBConnection(cfg)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
-	defer db.Close()
 
 	// 1. リポジトリの初期化
 	// ActionLogRepository が必要になったので追加します
 	userRepo := repository.NewUserRepository(db)
 	shiftRepo := repository.NewShiftRepository(db)
-	//notificationRepo := repository.NewNotificationRepository(db)
 	actionLogRepo := repository.NewActionLogRepository(db) // ★追加
 	shiftReadRepo := repository.NewShiftReadRepository(db) // ★追加
+	prefRepo := repository.NewNotificationPreferenceRepository(db)
+	taskLeadRepo := repository.NewTaskLeadRepository(db)
+	staffingRequirementRepo := repository.NewStaffingRequirementRepository(db)
+	syncRunRepo := repository.NewSyncRunRepository(db)
+	deliveryRepo := repository.NewNotificationDeliveryRepository(db)
+	auditLogRepo := repository.NewAuditLogRepository(db)
+	calendarTokenRepo := repository.NewCalendarTokenRepository(db)
+	swapRepo := repository.NewShiftSwapRepository(db)
+	availabilityRepo := repository.NewAvailabilityRepository(db)
+	escalationRepo := repository.NewEscalationRepository(db)
 
 	// 2. サービスの初期化
 	// SlackServiceを先に作ります
 	slackService := service.NewSlackService(cfg)
+	emailService := service.NewEmailService(cfg)
+	deliveryService := service.NewDeliveryService(deliveryRepo)
+	debouncer := service.NewNotificationDebouncer(cfg.NotificationDebounce, slackService, emailService, deliveryService)
+	changeBroker := service.NewChangeBroker()
+	shiftCalendar := service.NewShiftCalendar(cfg)
+	prefService := service.NewPreferenceService(cfg, prefRepo, userRepo, shiftCalendar)
+	taskLeadService := service.NewTaskLeadService(taskLeadRepo, userRepo, prefService, slackService, emailService, deliveryService)
+	staffingService := service.NewStaffingService(cfg, db, staffingRequirementRepo, slackService, deliveryService, shiftCalendar)
+	availabilityService := service.NewAvailabilityService(db, availabilityRepo)
 
 	// ShiftServiceには、DB(トランザクション用)と、ログRepo、SlackServiceなど全てを渡します
 	shiftService := service.NewShiftService(
@@ -48,40 +75,256 @@ func main() {
 		actionLogRepo,
 		slackService,
 		shiftReadRepo,
+		prefService,
+		emailService,
+		taskLeadService,
+		staffingService,
+		syncRunRepo,
+		deliveryService,
+		debouncer,
+		changeBroker,
+		availabilityService,
+		swapRepo,
 	)
 
+	// まとめ送信(digest)を選んだユーザー向けの定期ジョブ
+	userDigestService := service.NewUserDigestService(
+		prefRepo,
+		userRepo,
+		actionLogRepo,
+		prefService,
+		slackService,
+		emailService,
+		deliveryService,
+		cfg.UserDigestInterval,
+	)
+
+	// 開催直前の変更が未読のままなら、本人への再送 -> タスクリーダーへの通知
+	escalationService := service.NewEscalationService(
+		cfg,
+		escalationRepo,
+		userRepo,
+		taskLeadService,
+		prefService,
+		slackService,
+		emailService,
+		deliveryService,
+		shiftCalendar,
+	)
+
+	// 運営チャンネルへの日次まとめ
+	channelDigestService := service.NewChannelDigestService(cfg, actionLogRepo, slackService, shiftCalendar)
+
+	// シフト交換の申請（相手の承諾 -> リーダーの承認で shifts に反映）
+	swapService := service.NewSwapService(
+		db,
+		swapRepo,
+		shiftRepo,
+		userRepo,
+		actionLogRepo,
+		shiftReadRepo,
+		syncRunRepo,
+		taskLeadService,
+		prefService,
+		slackService,
+		emailService,
+		deliveryService,
+		changeBroker,
+	)
+
+	// アプリ向けの通知一覧（action_log + shift_reads）
+	notificationService := service.NewNotificationService(actionLogRepo)
+	readService := service.NewReadService(shiftRepo, shiftReadRepo, changeBroker)
+	historyService := service.NewHistoryService(shiftRepo, actionLogRepo)
+	scheduleService := service.NewScheduleService(shiftRepo, shiftCalendar)
+	calendarService := service.NewCalendarService(shiftRepo, userRepo, calendarTokenRepo, actionLogRepo, shiftCalendar)
+
+	// Sign in with Slack とAPIトークン
+	oidcProvider := service.NewOIDCProvider(cfg)
+	authService := service.NewAuthService(cfg, oidcProvider, userRepo, taskLeadRepo)
+	auditService := service.NewAuditService(auditLogRepo)
+	userService := service.NewUserService(db, userRepo)
+
 	// 3. ハンドラーの初期化
 	// ShiftHandlerは Service だけを受け取るシンプルな形になりました
 	shiftHandler := handler.NewShiftHandler(shiftService)
-
-	// 他のハンドラー（変更なし）
-	//notificationHandler := handler.NewNotificationHandler(notificationRepo)
-	//readHandler := handler.NewReadHandler(notificationRepo)
+	preferenceHandler := handler.NewPreferenceHandler(prefService)
+	taskLeadHandler := handler.NewTaskLeadHandler(taskLeadService)
+	digestHandler := handler.NewDigestHandler(channelDigestService)
+	staffingHandler := handler.NewStaffingHandler(staffingService)
+	deliveryHandler := handler.NewDeliveryHandler(deliveryService)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	readHandler := handler.NewReadHandler(readService)
+	historyHandler := handler.NewHistoryHandler(historyService)
+	scheduleHandler := handler.NewScheduleHandler(scheduleService)
+	calendarHandler := handler.NewCalendarHandler(calendarService)
+	swapHandler := handler.NewSwapHandler(swapService)
+	availabilityHandler := handler.NewAvailabilityHandler(availabilityService)
+	escalationHandler := handler.NewEscalationHandler(escalationService)
+	eventHandler := handler.NewEventHandler(notificationService, readService, changeBroker, cfg.SSEHeartbeatInterval)
+	authHandler := handler.NewAuthHandler(authService, cfg.AuthSuccessURL)
+	userHandler := handler.NewUserHandler(userService)
+	auditHandler := handler.NewAuditHandler(auditService)
 
 	// Echoインスタンスの作成
 	e := echo.New()
+	e.HTTPErrorHandler = handler.HTTPErrorHandler // ルーティングのエラーなども同じ形で返す
 
 	// ミドルウェア
-	e.Use(middleware.Logger())
+	// URLのクエリにはトークン（?access_token= や カレンダーの ?token=）が入るので、ログにはパスだけを残す
+	e.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
+		Format: `{"time":"${time_rfc3339_nano}","id":"${id}","remote_ip":"${remote_ip}",` +
+			`"host":"${host}","method":"${method}","path":"${path}","user_agent":"${user_agent}",` +
+			`"status":${status},"error":"${error}","latency":${latency},"latency_human":"${latency_human}"` +
+			`,"bytes_in":${bytes_in},"bytes_out":${bytes_out}}` + "\n",
+	}))
 	e.Use(middleware.Recover())
 
 	// CORS設定
 	corsConfig := middleware.CORSConfig{
 		AllowOrigins: cfg.CORSAllowOrigins,
 		AllowMethods: []string{echo.GET, echo.POST, echo.PUT, echo.DELETE, echo.OPTIONS},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, "If-None-Match"},
+		// Webアプリからスケジュール表の ETag を読めるようにする
+		ExposeHeaders: []string{"ETag"},
 	}
 	e.Use(middleware.CORSWithConfig(corsConfig))
 
 	// ルーティング
 	api := e.Group("/api")
-	api.POST("/update_shifts", shiftHandler.UpdateShifts)
-	//api.GET("/notifications", notificationHandler.GetNotifications)
-	//api.POST("/notifications/:id/read", readHandler.MarkAsRead)
+	api.GET("/openapi.yaml", handler.GetOpenAPI)
 
-	// サーバー起動
+	// GAS（スプレッドシート）向け。共有キー(X-GAS-Key)か管理者のトークンが必要
+	gasOnly := handler.RequireGASOrAdmin(cfg.GASAPIKey, authService)
+	api.POST("/update_shifts", shiftHandler.UpdateShifts, gasOnly)
+	api.GET("/availability/ng_slots", availabilityHandler.GetNGSlots, gasOnly) // シートに書き込むNGの枠
+	api.GET("/shift_swaps/exports", swapHandler.GetExports, gasOnly)           // シートに書き戻す交換
+	api.POST("/shift_swaps/exports/ack", swapHandler.AckExports, gasOnly)
+
+	api.GET("/auth/slack/login", authHandler.SlackLogin)
+	api.GET("/auth/slack/callback", authHandler.SlackCallback)
+	api.GET("/users/:id/calendar.ics", calendarHandler.GetFeed) // カレンダーアプリからの購読（URLのトークンで認証）
+
+	// アプリへのリアルタイム配信(SSE)。EventSource はヘッダーを付けられないので ?access_token= も受け付ける
+	api.GET("/events", eventHandler.StreamEvents, handler.RequireStreamAuth(authService))
+
+	// ここから下はログインが必要（ユーザーは user_id ではなくトークンで決まる）
+	authed := api.Group("", handler.RequireAuth(authService))
+	authed.GET("/auth/me", authHandler.Me)
+	authed.GET("/users/:id/notification_preferences", preferenceHandler.GetPreference)
+	authed.PUT("/users/:id/notification_preferences", preferenceHandler.UpdatePreference)
+	authed.GET("/notifications", notificationHandler.GetNotifications)
+	authed.POST("/shifts/:id/read", readHandler.MarkShiftRead)
+	authed.POST("/shifts/read", readHandler.MarkShiftsRead)
+	authed.GET("/shifts/:id/history", historyHandler.GetShiftHistory)
+	authed.GET("/users/:id/unread_count", readHandler.GetUnreadCount)
+	authed.GET("/users/:id/timeline", notificationHandler.GetTimeline)
+	authed.GET("/schedule/grid", scheduleHandler.GetGrid)
+	authed.GET("/shifts", scheduleHandler.SearchShifts)
+	authed.GET("/tasks", scheduleHandler.GetTasks)
+	authed.GET("/tasks/:name/roster", scheduleHandler.GetTaskRoster)
+	authed.POST("/users/:id/calendar_token", calendarHandler.IssueToken)
+	authed.GET("/shift_swaps", swapHandler.GetSwaps)
+	authed.POST("/shift_swaps", swapHandler.CreateSwap)
+	authed.POST("/shift_swaps/:id/accept", swapHandler.AcceptSwap)
+	authed.POST("/shift_swaps/:id/decline", swapHandler.DeclineSwap)
+	authed.POST("/shift_swaps/:id/cancel", swapHandler.CancelSwap)
+	authed.GET("/users/:id/availability", availabilityHandler.GetAvailability)
+	authed.PUT("/users/:id/availability", availabilityHandler.SubmitAvailability)
+
+	// タスクリーダー以上（lead は担当タスクの分だけ見える・操作できる）
+	// 拒否された操作も残すため、監査を権限チェックより先に置く
+	leads := authed.Group("", handler.AuditPrivileged(auditService), handler.RequireRole(model.RoleAdmin, model.RoleLead))
+	leads.GET("/task_leads", taskLeadHandler.GetTaskLeads)
+	leads.POST("/task_leads", taskLeadHandler.AddTaskLead)
+	leads.DELETE("/task_leads", taskLeadHandler.RemoveTaskLead)
+	leads.GET("/staffing_requirements", staffingHandler.GetRequirements)
+	leads.GET("/staffing_requirements/coverage", staffingHandler.GetCoverage)
+	leads.GET("/shift_swaps/approvals", swapHandler.GetPendingApprovals)
+	leads.POST("/shift_swaps/:id/approve", swapHandler.ApproveSwap)
+	leads.POST("/shift_swaps/:id/reject", swapHandler.RejectSwap)
+
+	// 管理者のみ
+	admin := authed.Group("", handler.AuditPrivileged(auditService), handler.RequireRole(model.RoleAdmin))
+	admin.GET("/digests/changes", digestHandler.GetChangeDigest)
+	admin.POST("/digests/changes", digestHandler.PostChangeDigest)
+	admin.PUT("/staffing_requirements", staffingHandler.ReplaceRequirements)
+	admin.GET("/deliveries", deliveryHandler.GetDeliveries)
+	admin.GET("/users", userHandler.GetUsers)
+	admin.POST("/users", userHandler.CreateUser)
+	admin.PUT("/users/:id", userHandler.UpdateUser)
+	admin.DELETE("/users/:id", userHandler.DeactivateUser) // 利用停止（シフトと履歴は残す）
+	admin.PUT("/users/:id/role", userHandler.UpdateRole)
+	admin.GET("/audit_log", auditHandler.GetAuditLog)
+	admin.GET("/availability", availabilityHandler.ListAvailability)
+	admin.GET("/unacknowledged_changes", escalationHandler.GetUnacknowledged)
+
+	// SIGINT / SIGTERM を受け取ったら ctx が終了する
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 定期ジョブの起動（シャットダウン時に jobsCtx で止める）
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	var jobs sync.WaitGroup
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		userDigestService.Run(jobsCtx)
+	}()
+	if cfg.ChannelDigestEnabled {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			channelDigestService.Run(jobsCtx)
+		}()
+	}
+	if cfg.UnackEscalationEnabled {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			escalationService.Run(jobsCtx)
+		}()
+	}
+
+	// サーバー起動（別goroutineで動かし、メインはシグナルを待つ）
 	port := fmt.Sprintf(":%s", cfg.APIPort)
-	if err := e.Start(port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	go func() {
+		if err := e.Start(port); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop() // 2回目のシグナルでは即時終了させる
+	log.Println("Shutdown signal received, shutting down gracefully...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// 1. 新規リクエストの受付を止め、処理中の同期(SyncShifts)が終わるのを待つ
+	// SSEの接続は終わらないので、先に閉じておく
+	changeBroker.Close()
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to shutdown server gracefully: %v", err)
+	}
+
+	// 2. 定期ジョブを止める
+	stopJobs()
+	jobs.Wait()
+
+	// 3. 保留中の通知をキューに移し、Slack・メールのキューに残った通知を期限内で送り切ってワーカーを止める
+	debouncer.Flush()
+	if err := slackService.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to drain slack queue: %v", err)
+	}
+	if err := emailService.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to drain email queue: %v", err)
 	}
+
+	// 4. 最後にDBを閉じる
+	if err := db.Close(); err != nil {
+		log.Printf("Failed to close database: %v", err)
+	}
+
+	log.Println("Server stopped")
 }
@@ -0,0 +1,9 @@
+#!/bin/sh
+# 新しいDBの初期化時に、マイグレーションの up だけを番号順に実行する
+# （migrations ディレクトリをそのまま /docker-entrypoint-initdb.d に置くと、down も up より先に実行されてしまう）
+set -e
+
+for f in /migrations/*.up.sql; do
+    echo "migrate: $f"
+    psql -v ON_ERROR_STOP=1 --username "$POSTGRES_USER" --dbname "$POSTGRES_DB" -f "$f"
+done
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS notification_preferences;
+ALTER TABLE users DROP COLUMN IF EXISTS email;
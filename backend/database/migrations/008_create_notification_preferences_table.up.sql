@@ -0,0 +1,14 @@
+ALTER TABLE users ADD COLUMN email VARCHAR(255);
+
+CREATE TABLE notification_preferences (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    notify_create BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_update BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_delete BOOLEAN NOT NULL DEFAULT TRUE,
+    delivery_channel VARCHAR(20) NOT NULL DEFAULT 'dm',
+    delivery_mode VARCHAR(20) NOT NULL DEFAULT 'realtime',
+    include_inactive_weather BOOLEAN NOT NULL DEFAULT TRUE,
+    within_hours INTEGER NOT NULL DEFAULT 0,
+    last_digest_at TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_task_leads_user_id;
+DROP TABLE IF EXISTS task_leads;
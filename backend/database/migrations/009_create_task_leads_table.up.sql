@@ -0,0 +1,8 @@
+CREATE TABLE task_leads (
+    task_name VARCHAR(255) NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (task_name, user_id)
+);
+
+CREATE INDEX idx_task_leads_user_id ON task_leads(user_id);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS staffing_requirements;
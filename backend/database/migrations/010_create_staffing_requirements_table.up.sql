@@ -0,0 +1,10 @@
+CREATE TABLE staffing_requirements (
+    id SERIAL PRIMARY KEY,
+    task_name VARCHAR(255) NOT NULL,
+    date VARCHAR(50) NOT NULL,
+    weather VARCHAR(50) NOT NULL,
+    time_id INTEGER NOT NULL,
+    min_staff INTEGER NOT NULL CHECK (min_staff >= 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(task_name, date, weather, time_id)
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS notification_deliveries;
+DROP INDEX IF EXISTS idx_action_log_sync_id;
+ALTER TABLE action_log DROP COLUMN IF EXISTS sync_id;
+DROP TABLE IF EXISTS sync_runs;
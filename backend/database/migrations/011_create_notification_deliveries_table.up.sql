@@ -0,0 +1,30 @@
+CREATE TABLE sync_runs (
+    id SERIAL PRIMARY KEY,
+    change_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE action_log ADD COLUMN sync_id INTEGER REFERENCES sync_runs(id);
+
+CREATE TABLE notification_deliveries (
+    id SERIAL PRIMARY KEY,
+    action_log_id INTEGER REFERENCES action_log(id),
+    sync_id INTEGER REFERENCES sync_runs(id),
+    shift_id INTEGER REFERENCES shifts(id),
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    kind VARCHAR(50) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    recipient VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    slack_ts VARCHAR(50),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_action_log_sync_id ON action_log(sync_id);
+CREATE INDEX idx_notification_deliveries_user_id ON notification_deliveries(user_id);
+CREATE INDEX idx_notification_deliveries_shift_id ON notification_deliveries(shift_id);
+CREATE INDEX idx_notification_deliveries_sync_id ON notification_deliveries(sync_id);
+CREATE INDEX idx_notification_deliveries_action_log_id ON notification_deliveries(action_log_id);
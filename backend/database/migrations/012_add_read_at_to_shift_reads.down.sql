@@ -0,0 +1 @@
+ALTER TABLE shift_reads DROP COLUMN IF EXISTS read_at;
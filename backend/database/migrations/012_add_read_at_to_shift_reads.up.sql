@@ -0,0 +1,4 @@
+-- 既読にした日時。この日時より前の変更を既読として扱う
+ALTER TABLE shift_reads ADD COLUMN read_at TIMESTAMP;
+
+UPDATE shift_reads SET read_at = updated_at WHERE is_read = TRUE;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_audit_log_created_at;
+DROP INDEX IF EXISTS idx_audit_log_actor_user_id;
+DROP TABLE IF EXISTS audit_log;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
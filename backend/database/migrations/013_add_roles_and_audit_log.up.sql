@@ -0,0 +1,17 @@
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'member'
+    CHECK (role IN ('admin', 'lead', 'member'));
+
+CREATE TABLE audit_log (
+    id SERIAL PRIMARY KEY,
+    actor_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    actor_name VARCHAR(255) NOT NULL DEFAULT '',
+    actor_role VARCHAR(20) NOT NULL DEFAULT '',
+    action VARCHAR(255) NOT NULL,
+    target VARCHAR(255) NOT NULL DEFAULT '',
+    status INTEGER NOT NULL DEFAULT 0,
+    detail JSONB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_log_actor_user_id ON audit_log(actor_user_id);
+CREATE INDEX idx_audit_log_created_at ON audit_log(created_at);
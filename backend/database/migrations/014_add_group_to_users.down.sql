@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_users_user_group;
+ALTER TABLE users DROP COLUMN IF EXISTS user_group;
@@ -0,0 +1,4 @@
+-- ボランティアの所属グループ（"1年" や "実行委員" など）。スケジュール表の絞り込みに使う
+ALTER TABLE users ADD COLUMN user_group VARCHAR(100) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_users_user_group ON users(user_group);
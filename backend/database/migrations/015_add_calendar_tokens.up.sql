@@ -0,0 +1,7 @@
+-- カレンダー購読用URLのトークン（ユーザーごとに1つ。再発行すると古いURLは使えなくなる）
+-- トークンそのものは保存せず、SHA-256 のハッシュだけを持つ
+CREATE TABLE calendar_tokens (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    token_hash CHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,24 @@
+-- シフト交換の申請
+-- 申請者のシフトと相手のシフトを入れ替える。相手の承諾 -> タスクリーダーの承認で shifts に反映する
+-- *_task_name は申請時点のタスク名（反映時にシフトが変わっていないかの確認に使う）
+CREATE TABLE shift_swaps (
+    id SERIAL PRIMARY KEY,
+    requester_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    requester_shift_id INTEGER NOT NULL REFERENCES shifts(id),
+    requester_task_name VARCHAR(255) NOT NULL,
+    counterpart_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    counterpart_shift_id INTEGER NOT NULL REFERENCES shifts(id),
+    counterpart_task_name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'accepted', 'declined', 'approved', 'rejected', 'cancelled')),
+    message TEXT NOT NULL DEFAULT '',
+    approver_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    sync_id INTEGER REFERENCES sync_runs(id),
+    exported_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_shift_swaps_requester_id ON shift_swaps(requester_id);
+CREATE INDEX idx_shift_swaps_counterpart_id ON shift_swaps(counterpart_id);
+CREATE INDEX idx_shift_swaps_status ON shift_swaps(status);
@@ -0,0 +1,18 @@
+-- ボランティアが申告した参加できない時間帯 (NG)
+-- シフトの割り当て (shifts) とは別に持ち、同期でNGの枠に割り当てられていないかの確認に使う
+-- start_time_id から end_time_id の手前までの枠がNG
+CREATE TABLE availability_ng (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    year_id INTEGER NOT NULL,
+    date VARCHAR(50) NOT NULL,
+    weather VARCHAR(50) NOT NULL,
+    start_time_id INTEGER NOT NULL,
+    end_time_id INTEGER NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (start_time_id < end_time_id)
+);
+
+CREATE INDEX idx_availability_ng_user_year ON availability_ng(user_id, year_id);
+CREATE INDEX idx_availability_ng_year_date_weather ON availability_ng(year_id, date, weather);
@@ -0,0 +1,8 @@
+-- 未読のままの変更への催促の記録（同じ変更に同じ段階の催促を二度送らないため）
+-- stage: reminder = 本人への再送, lead = タスクリーダーへの通知
+CREATE TABLE change_escalations (
+    action_log_id INTEGER NOT NULL REFERENCES action_log(id) ON DELETE CASCADE,
+    stage VARCHAR(20) NOT NULL CHECK (stage IN ('reminder', 'lead')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (action_log_id, stage)
+);
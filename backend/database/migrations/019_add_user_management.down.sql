@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS user_name_aliases;
+ALTER TABLE users DROP COLUMN IF EXISTS deactivated_at;
+ALTER TABLE users DROP COLUMN IF EXISTS locale;
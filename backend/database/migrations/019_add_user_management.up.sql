@@ -0,0 +1,12 @@
+-- 通知などの言語と、利用停止日時（停止中はログインできない）
+ALTER TABLE users ADD COLUMN locale VARCHAR(20) NOT NULL DEFAULT 'ja';
+ALTER TABLE users ADD COLUMN deactivated_at TIMESTAMP;
+
+-- 改名前の名前（シートが古い名前のままでも同期でシフトを引き継ぐため）
+CREATE TABLE IF NOT EXISTS user_name_aliases (
+    name VARCHAR(255) PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_name_aliases_user_id ON user_name_aliases(user_id);
@@ -0,0 +1 @@
+ALTER TABLE shift_swaps DROP COLUMN IF EXISTS approved_tasks;
@@ -0,0 +1,2 @@
+-- 交換の承認を済ませたタスク（交換する両方のタスクのリーダーが承認すると shifts に反映する）
+ALTER TABLE shift_swaps ADD COLUMN approved_tasks TEXT[] NOT NULL DEFAULT '{}';
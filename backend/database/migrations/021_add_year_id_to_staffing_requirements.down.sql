@@ -0,0 +1,19 @@
+-- 年度をまとめると重なる行は、年度の大きいものだけ残す（year_id がまだ無ければ何もしない）
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'staffing_requirements' AND column_name = 'year_id'
+    ) THEN
+        DELETE FROM staffing_requirements r
+        USING staffing_requirements newer
+        WHERE newer.task_name = r.task_name AND newer.date = r.date AND newer.weather = r.weather
+            AND newer.time_id = r.time_id AND newer.year_id > r.year_id;
+    END IF;
+END $$;
+
+ALTER TABLE staffing_requirements DROP CONSTRAINT IF EXISTS staffing_requirements_year_id_task_name_date_weather_time_id_key;
+ALTER TABLE staffing_requirements DROP CONSTRAINT IF EXISTS staffing_requirements_task_name_date_weather_time_id_key;
+ALTER TABLE staffing_requirements ADD CONSTRAINT staffing_requirements_task_name_date_weather_time_id_key
+    UNIQUE (task_name, date, weather, time_id);
+ALTER TABLE staffing_requirements DROP COLUMN IF EXISTS year_id;
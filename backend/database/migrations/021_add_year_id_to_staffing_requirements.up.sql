@@ -0,0 +1,10 @@
+-- 必要人数を年度ごとに持つ（配置人数を他の年度のシフトと混ぜて数えないため）
+-- 既存の行は最新の年度のものとみなす（シフトが1件も無ければ比べる相手が無いので消す。次の送信で入り直す）
+ALTER TABLE staffing_requirements ADD COLUMN year_id INTEGER;
+UPDATE staffing_requirements SET year_id = (SELECT MAX(year_id) FROM shifts);
+DELETE FROM staffing_requirements WHERE year_id IS NULL;
+ALTER TABLE staffing_requirements ALTER COLUMN year_id SET NOT NULL;
+
+ALTER TABLE staffing_requirements DROP CONSTRAINT staffing_requirements_task_name_date_weather_time_id_key;
+ALTER TABLE staffing_requirements ADD CONSTRAINT staffing_requirements_year_id_task_name_date_weather_time_id_key
+    UNIQUE (year_id, task_name, date, weather, time_id);
@@ -0,0 +1,10 @@
+// Package apidoc APIの OpenAPI ドキュメント
+// GAS や Flutter のクライアントはこのドキュメントから生成する
+package apidoc
+
+import _ "embed"
+
+// Spec OpenAPI 3.0 のドキュメント（YAML）
+//
+//go:embed openapi.yaml
+var Spec []byte
@@ -3,21 +3,79 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	DBHost          string
-	DBPort          string
-	DBUser          string
-	DBPassword      string
-	DBName          string
-	SlackBotToken   string
-	SlackChannelID  string
-	APIPort         string
+	DBHost           string
+	DBPort           string
+	DBUser           string
+	DBPassword       string
+	DBName           string
+	SlackBotToken    string
+	SlackChannelID   string
+	SlackAPIURL      string // 空なら本物のSlack。開発時は cmd/fakeslack に向ける
+	APIPort          string
 	CORSAllowOrigins []string
+	ShutdownTimeout  time.Duration // SIGINT/SIGTERM受信後、終了処理に使える最大時間
+	SlackWorkerCount int           // Slack通知を並列に送るワーカー数
+	SlackRateLimit   float64       // ワーカー全体での1秒あたりの最大送信数
+
+	// 開催日程: 日付ラベル("1日目"など) -> 実際の日付(0時)
+	EventDates    map[string]time.Time
+	EventLocation *time.Location
+	// 実施する天気プラン("晴れ"/"雨")。空なら両方とも有効として扱う
+	ActiveWeather string
+
+	// 人員不足アラートの投稿先（未指定なら SLACK_CHANNEL_ID）
+	StaffingAlertChannelID string
+
+	// まとめ送信(digest)を選んだユーザーへの送信間隔
+	UserDigestInterval time.Duration
+
+	// 同じ枠への変更をまとめるために即時通知を保留する時間（0なら保留しない）
+	NotificationDebounce time.Duration
+
+	// SSE接続を生かしておくためのハートビート間隔
+	SSEHeartbeatInterval time.Duration
+
+	// 運営チャンネルへの日次まとめの投稿時刻（開催地のタイムゾーン基準）
+	ChannelDigestEnabled bool
+	ChannelDigestHour    int
+	ChannelDigestMinute  int
+
+	// 開催直前の変更が既読にならないときの催促
+	// 開始まで UnackWindow 以内のシフトへの変更が、UnackRemindAfter 経っても未読なら本人に再送し、
+	// UnackLeadAfter 経っても未読ならタスクリーダーに知らせる
+	UnackEscalationEnabled bool
+	UnackWindow            time.Duration
+	UnackRemindAfter       time.Duration
+	UnackLeadAfter         time.Duration
+	UnackCheckInterval     time.Duration
+
+	// Sign in with Slack (OpenID Connect)
+	OIDCIssuer        string // 開発時は cmd/fakeslack に向ける
+	SlackClientID     string
+	SlackClientSecret string
+	OIDCRedirectURL   string // /api/auth/slack/callback の外から見たURL
+	// APIトークンの署名鍵と有効期限
+	AuthTokenSecret string
+	AuthTokenTTL    time.Duration
+	// ログイン後にトークンを渡すフロントエンドのURL（空ならJSONで返す）
+	AuthSuccessURL string
+	// GAS（スプレッドシート）向けAPIの共有キー（X-GAS-Key ヘッダーで渡す。空なら管理者のトークンのみ受け付ける）
+	GASAPIKey string
+
+	// メール通知(SMTP)。SMTPHostが空ならメール送信は無効
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUser     string
+	SMTPPassword string
+	SMTPFrom     string
 }
 
 func LoadConfig() (*Config, error) {
@@ -38,16 +96,125 @@ func LoadConfig() (*Config, error) {
 		}
 	}
 
+	shutdownTimeout, err := getEnvDuration("SHUTDOWN_TIMEOUT", 15*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	slackWorkerCount, err := getEnvInt("SLACK_WORKER_COUNT", 4)
+	if err != nil {
+		return nil, err
+	}
+
+	slackRateLimit, err := getEnvFloat("SLACK_RATE_LIMIT", 5)
+	if err != nil {
+		return nil, err
+	}
+
+	eventLocation, err := time.LoadLocation(getEnv("EVENT_TIMEZONE", "Asia/Tokyo"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EVENT_TIMEZONE: %w", err)
+	}
+
+	eventDates, err := parseEventDates(getEnv("EVENT_DATES", ""), eventLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	userDigestInterval, err := getEnvDuration("USER_DIGEST_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	notificationDebounce, err := getEnvDuration("NOTIFICATION_DEBOUNCE", 2*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	sseHeartbeatInterval, err := getEnvDuration("SSE_HEARTBEAT_INTERVAL", 15*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	authTokenTTL, err := getEnvDuration("AUTH_TOKEN_TTL", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	unackWindow, err := getEnvDuration("UNACK_WINDOW", 48*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	unackRemindAfter, err := getEnvDuration("UNACK_REMIND_AFTER", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	unackLeadAfter, err := getEnvDuration("UNACK_LEAD_AFTER", 3*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	unackCheckInterval, err := getEnvDuration("UNACK_CHECK_INTERVAL", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	channelDigestTime, err := time.Parse("15:04", getEnv("CHANNEL_DIGEST_TIME", "09:00"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CHANNEL_DIGEST_TIME: %w", err)
+	}
+
 	config := &Config{
-		DBHost:          getEnv("DB_HOST", "localhost"),
-		DBPort:          getEnv("DB_PORT", "5432"),
-		DBUser:          getEnv("DB_USER", "postgres"),
-		DBPassword:      getEnv("DB_PASSWORD", "postgres"),
-		DBName:          getEnv("DB_NAME", "seeft_shift"),
-		SlackBotToken:   getEnv("SLACK_BOT_TOKEN", ""),
-		SlackChannelID:  getEnv("SLACK_CHANNEL_ID", ""),
-		APIPort:         getEnv("API_PORT", "8080"),
+		DBHost:           getEnv("DB_HOST", "localhost"),
+		DBPort:           getEnv("DB_PORT", "5432"),
+		DBUser:           getEnv("DB_USER", "postgres"),
+		DBPassword:       getEnv("DB_PASSWORD", "postgres"),
+		DBName:           getEnv("DB_NAME", "seeft_shift"),
+		SlackBotToken:    getEnv("SLACK_BOT_TOKEN", ""),
+		SlackChannelID:   getEnv("SLACK_CHANNEL_ID", ""),
+		SlackAPIURL:      getEnv("SLACK_API_URL", ""),
+		APIPort:          getEnv("API_PORT", "8080"),
 		CORSAllowOrigins: corsOrigins,
+		ShutdownTimeout:  shutdownTimeout,
+		SlackWorkerCount: slackWorkerCount,
+		SlackRateLimit:   slackRateLimit,
+
+		EventDates:         eventDates,
+		EventLocation:      eventLocation,
+		ActiveWeather:      getEnv("ACTIVE_WEATHER", ""),
+		UserDigestInterval: userDigestInterval,
+
+		NotificationDebounce: notificationDebounce,
+		SSEHeartbeatInterval: sseHeartbeatInterval,
+
+		StaffingAlertChannelID: getEnv("STAFFING_ALERT_CHANNEL_ID", getEnv("SLACK_CHANNEL_ID", "")),
+
+		ChannelDigestEnabled: getEnv("CHANNEL_DIGEST_ENABLED", "true") == "true",
+		ChannelDigestHour:    channelDigestTime.Hour(),
+		ChannelDigestMinute:  channelDigestTime.Minute(),
+
+		UnackEscalationEnabled: getEnv("UNACK_ESCALATION_ENABLED", "true") == "true",
+		UnackWindow:            unackWindow,
+		UnackRemindAfter:       unackRemindAfter,
+		UnackLeadAfter:         unackLeadAfter,
+		UnackCheckInterval:     unackCheckInterval,
+
+		OIDCIssuer:        getEnv("OIDC_ISSUER", "https://slack.com"),
+		SlackClientID:     getEnv("SLACK_CLIENT_ID", ""),
+		SlackClientSecret: getEnv("SLACK_CLIENT_SECRET", ""),
+		OIDCRedirectURL:   getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/api/auth/slack/callback"),
+		AuthTokenSecret:   getEnv("AUTH_TOKEN_SECRET", ""),
+		AuthTokenTTL:      authTokenTTL,
+		AuthSuccessURL:    getEnv("AUTH_SUCCESS_URL", ""),
+		GASAPIKey:         getEnv("GAS_API_KEY", ""),
+
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUser:     getEnv("SMTP_USER", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:     getEnv("SMTP_FROM", ""),
 	}
 
 	// 必須項目のチェック
@@ -57,6 +224,33 @@ func LoadConfig() (*Config, error) {
 	if config.SlackChannelID == "" {
 		return nil, fmt.Errorf("SLACK_CHANNEL_ID is required")
 	}
+	if config.AuthTokenSecret == "" {
+		return nil, fmt.Errorf("AUTH_TOKEN_SECRET is required")
+	}
+	if config.AuthTokenTTL <= 0 {
+		return nil, fmt.Errorf("AUTH_TOKEN_TTL must be positive")
+	}
+	if config.SlackWorkerCount < 1 {
+		return nil, fmt.Errorf("SLACK_WORKER_COUNT must be at least 1")
+	}
+	if config.SlackRateLimit <= 0 {
+		return nil, fmt.Errorf("SLACK_RATE_LIMIT must be positive")
+	}
+	if config.UserDigestInterval <= 0 {
+		return nil, fmt.Errorf("USER_DIGEST_INTERVAL must be positive")
+	}
+	if config.NotificationDebounce < 0 {
+		return nil, fmt.Errorf("NOTIFICATION_DEBOUNCE must not be negative")
+	}
+	if config.SSEHeartbeatInterval <= 0 {
+		return nil, fmt.Errorf("SSE_HEARTBEAT_INTERVAL must be positive")
+	}
+	if config.UnackWindow <= 0 || config.UnackRemindAfter <= 0 || config.UnackCheckInterval <= 0 {
+		return nil, fmt.Errorf("UNACK_WINDOW, UNACK_REMIND_AFTER and UNACK_CHECK_INTERVAL must be positive")
+	}
+	if config.UnackLeadAfter <= config.UnackRemindAfter {
+		return nil, fmt.Errorf("UNACK_LEAD_AFTER must be longer than UNACK_REMIND_AFTER")
+	}
 
 	return config, nil
 }
@@ -68,3 +262,72 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvDuration "15s" や "1m" 形式の環境変数を time.Duration として読み込む
+func getEnvDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return d, nil
+}
+
+// getEnvInt 整数の環境変数を読み込む
+func getEnvInt(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return n, nil
+}
+
+// getEnvFloat 小数の環境変数を読み込む
+func getEnvFloat(key string, defaultValue float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return f, nil
+}
+
+// parseEventDates "準備日=2025-11-01,1日目=2025-11-02" 形式の開催日程を読み込む
+func parseEventDates(value string, loc *time.Location) (map[string]time.Time, error) {
+	dates := make(map[string]time.Time)
+	if value == "" {
+		return dates, nil
+	}
+
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		label, dateStr, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid EVENT_DATES entry: %q", part)
+		}
+
+		d, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(dateStr), loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EVENT_DATES date for %s: %w", label, err)
+		}
+		dates[strings.TrimSpace(label)] = d
+	}
+
+	return dates, nil
+}
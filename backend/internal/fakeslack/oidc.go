@@ -0,0 +1,236 @@
+package fakeslack
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"math/big"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// signingKeyID ID トークンの署名鍵の kid
+const signingKeyID = "fake"
+
+// fakeTeamID ID トークンに入れるワークスペースID
+const fakeTeamID = "T00000000"
+
+// authCode 発行済みの認可コード（1回だけ使える）
+type authCode struct {
+	member      Member
+	clientID    string
+	redirectURI string
+	nonce       string
+}
+
+// chooseMemberTemplate login_hint が無いときに出すユーザー選択画面
+var chooseMemberTemplate = template.Must(template.New("choose").Parse(`<!DOCTYPE html>
+<html lang="ja">
+<head><meta charset="utf-8"><title>Fake Slack - ログイン</title></head>
+<body>
+<h1>ログインするユーザーを選んでください</h1>
+<ul>
+{{range .}}<li><a href="{{.URL}}">{{.Member.RealName}} ({{.Member.ID}})</a></li>
+{{else}}<li>FAKE_SLACK_USERS にユーザーが登録されていません</li>
+{{end}}</ul>
+</body>
+</html>
+`))
+
+// registerOIDC Sign in with Slack (OpenID Connect) のスタブを登録する
+//
+//	GET  /.well-known/openid-configuration
+//	GET  /openid/connect/authorize    ユーザー選択画面（?login_hint=U... なら即リダイレクト）
+//	POST /api/openid.connect.token
+//	GET  /openid/connect/keys
+func (s *Server) registerOIDC(e *echo.Echo) {
+	e.GET("/.well-known/openid-configuration", s.openIDConfiguration)
+	e.GET("/openid/connect/authorize", s.authorize)
+	e.POST("/api/openid.connect.token", s.openIDToken)
+	e.GET("/openid/connect/keys", s.jwks)
+}
+
+// issuer リクエストを受けたホストを issuer とする（バックエンドから見えるURLと一致させるため）
+func issuer(c echo.Context) string {
+	return "http://" + c.Request().Host
+}
+
+// openIDConfiguration discovery ドキュメント
+// 認可エンドポイントだけはブラウザから開くので、FAKE_SLACK_PUBLIC_URL があればそちらを使う
+func (s *Server) openIDConfiguration(c echo.Context) error {
+	base := issuer(c)
+	public := base
+	if s.publicURL != "" {
+		public = s.publicURL
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"issuer":                                base,
+		"authorization_endpoint":                public + "/openid/connect/authorize",
+		"token_endpoint":                        base + "/api/openid.connect.token",
+		"jwks_uri":                              base + "/openid/connect/keys",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"scopes_supported":                      []string{"openid", "profile", "email"},
+	})
+}
+
+// authorize ユーザーを選ばせて、redirect_uri に認可コードを付けて戻す
+func (s *Server) authorize(c echo.Context) error {
+	clientID := c.QueryParam("client_id")
+	redirectURI := c.QueryParam("redirect_uri")
+	if clientID == "" || redirectURI == "" || c.QueryParam("response_type") != "code" {
+		return c.String(http.StatusBadRequest, "client_id, redirect_uri and response_type=code are required")
+	}
+
+	hint := c.QueryParam("login_hint")
+	if hint == "" {
+		type choice struct {
+			Member Member
+			URL    string
+		}
+		choices := make([]choice, 0, len(s.members))
+		for _, m := range s.members {
+			q := c.QueryParams()
+			q.Set("login_hint", m.ID)
+			choices = append(choices, choice{Member: m, URL: c.Path() + "?" + q.Encode()})
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+		return chooseMemberTemplate.Execute(c.Response(), choices)
+	}
+
+	member, ok := s.findMember(hint)
+	if !ok {
+		return c.String(http.StatusBadRequest, "unknown user: "+hint)
+	}
+
+	code, err := randomString()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.codes[code] = authCode{
+		member:      member,
+		clientID:    clientID,
+		redirectURI: redirectURI,
+		nonce:       c.QueryParam("nonce"),
+	}
+	s.mu.Unlock()
+
+	redirect, err := url.Parse(redirectURI)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "invalid redirect_uri")
+	}
+	q := redirect.Query()
+	q.Set("code", code)
+	q.Set("state", c.QueryParam("state"))
+	redirect.RawQuery = q.Encode()
+
+	return c.Redirect(http.StatusFound, redirect.String())
+}
+
+// openIDToken openid.connect.token（Slack と同じく失敗時も HTTP 200 + ok:false）
+func (s *Server) openIDToken(c echo.Context) error {
+	params, err := readParams(c)
+	if err != nil {
+		return slackError(c, "invalid_arguments")
+	}
+	delete(params, "client_secret")
+	s.record("openid.connect.token", params, "", "")
+
+	code := stringParam(params, "code")
+
+	s.mu.Lock()
+	issued, ok := s.codes[code]
+	delete(s.codes, code)
+	s.mu.Unlock()
+
+	if !ok {
+		return slackError(c, "invalid_code")
+	}
+	if stringParam(params, "client_id") != issued.clientID {
+		return slackError(c, "bad_client_secret")
+	}
+	if stringParam(params, "redirect_uri") != issued.redirectURI {
+		return slackError(c, "bad_redirect_uri")
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":                       issuer(c),
+		"sub":                       issued.member.ID,
+		"aud":                       issued.clientID,
+		"exp":                       now.Add(time.Hour).Unix(),
+		"iat":                       now.Unix(),
+		"auth_time":                 now.Unix(),
+		"nonce":                     issued.nonce,
+		"https://slack.com/user_id": issued.member.ID,
+		"https://slack.com/team_id": fakeTeamID,
+		"name":                      issued.member.RealName,
+	})
+	token.Header["kid"] = signingKeyID
+
+	idToken, err := token.SignedString(s.signingKey)
+	if err != nil {
+		return slackError(c, "internal_error")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ok":           true,
+		"access_token": "xoxp-fake-" + issued.member.ID,
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+	})
+}
+
+// jwks ID トークンの検証用の公開鍵
+func (s *Server) jwks(c echo.Context) error {
+	pub := s.signingKey.PublicKey
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": signingKeyID,
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+// findMember IDでメンバーを探す
+func (s *Server) findMember(id string) (Member, bool) {
+	for _, m := range s.members {
+		if m.ID == id {
+			return m, true
+		}
+	}
+	return Member{}, false
+}
+
+// newSigningKey ID トークンの署名鍵（起動ごとに作り直す）
+func newSigningKey() (*rsa.PrivateKey, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	return key, nil
+}
+
+// randomString 認可コード用のランダムな文字列
+func randomString() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
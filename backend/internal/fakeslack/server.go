@@ -0,0 +1,282 @@
+package fakeslack
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Call 受け付けたSlack API呼び出し1件分の記録
+type Call struct {
+	ID         int                    `json:"id"`
+	Method     string                 `json:"method"` // "chat.postMessage" など
+	Params     map[string]interface{} `json:"params"` // フォーム値 or JSONボディ（tokenは除く）
+	Channel    string                 `json:"channel,omitempty"`
+	TS         string                 `json:"ts,omitempty"`
+	ReceivedAt time.Time              `json:"received_at"`
+}
+
+// Member users.list で返すユーザー
+type Member struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	RealName string `json:"real_name"`
+}
+
+// Server 開発用のSlack API互換サーバー
+// 実際には何も送信せず、受け付けた呼び出しを記録して検査用エンドポイントで返す
+type Server struct {
+	mu      sync.Mutex
+	calls   []Call
+	nextID  int
+	nextTS  int64
+	members []Member
+
+	// Sign in with Slack (OIDC) のスタブ用
+	publicURL  string // ブラウザから見たこのサーバーのURL（空ならリクエストのホスト）
+	signingKey *rsa.PrivateKey
+	codes      map[string]authCode
+}
+
+func NewServer(members []Member, publicURL string) (*Server, error) {
+	key, err := newSigningKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		members:    members,
+		nextTS:     time.Now().Unix(),
+		publicURL:  strings.TrimSuffix(publicURL, "/"),
+		signingKey: key,
+		codes:      make(map[string]authCode),
+	}, nil
+}
+
+// Handler Slack API (/api/*)・OIDC のスタブ・検査用エンドポイント (/_calls) を持つハンドラを返す
+func (s *Server) Handler() http.Handler {
+	e := echo.New()
+	e.HideBanner = true
+
+	api := e.Group("/api")
+	api.Any("/chat.postMessage", s.postMessage)
+	api.Any("/chat.update", s.update)
+	api.Any("/users.list", s.usersList)
+	api.Any("/views.publish", s.viewsPublish)
+	api.Any("/auth.test", s.authTest)
+
+	s.registerOIDC(e)
+
+	e.GET("/_calls", s.listCalls)
+	e.DELETE("/_calls", s.clearCalls)
+
+	return e
+}
+
+// postMessage chat.postMessage
+func (s *Server) postMessage(c echo.Context) error {
+	params, err := readParams(c)
+	if err != nil {
+		return slackError(c, "invalid_arguments")
+	}
+
+	channel := stringParam(params, "channel")
+	if channel == "" {
+		return slackError(c, "channel_not_found")
+	}
+
+	ts := s.issueTS()
+	s.record("chat.postMessage", params, channel, ts)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ok":      true,
+		"channel": channel,
+		"ts":      ts,
+		"message": map[string]interface{}{
+			"type": "message",
+			"text": stringParam(params, "text"),
+			"ts":   ts,
+		},
+	})
+}
+
+// update chat.update
+func (s *Server) update(c echo.Context) error {
+	params, err := readParams(c)
+	if err != nil {
+		return slackError(c, "invalid_arguments")
+	}
+
+	channel := stringParam(params, "channel")
+	ts := stringParam(params, "ts")
+	if channel == "" || ts == "" {
+		return slackError(c, "message_not_found")
+	}
+
+	s.record("chat.update", params, channel, ts)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ok":      true,
+		"channel": channel,
+		"ts":      ts,
+		"text":    stringParam(params, "text"),
+	})
+}
+
+// usersList users.list（ページングは行わず全員を返す）
+func (s *Server) usersList(c echo.Context) error {
+	params, err := readParams(c)
+	if err != nil {
+		return slackError(c, "invalid_arguments")
+	}
+	s.record("users.list", params, "", "")
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ok":      true,
+		"members": s.members,
+		"response_metadata": map[string]string{
+			"next_cursor": "",
+		},
+	})
+}
+
+// viewsPublish views.publish（ホームタブ）
+func (s *Server) viewsPublish(c echo.Context) error {
+	params, err := readParams(c)
+	if err != nil {
+		return slackError(c, "invalid_arguments")
+	}
+
+	userID := stringParam(params, "user_id")
+	if userID == "" {
+		return slackError(c, "invalid_arguments")
+	}
+	s.record("views.publish", params, userID, "")
+
+	view, _ := params["view"].(map[string]interface{})
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ok":   true,
+		"view": view,
+	})
+}
+
+// authTest auth.test（トークンの確認は行わない）
+func (s *Server) authTest(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ok":      true,
+		"team":    "fake-workspace",
+		"user":    "fake-bot",
+		"team_id": "T00000000",
+		"user_id": "U00000000",
+	})
+}
+
+// listCalls 記録した呼び出しを返す（?method= で絞り込み）
+func (s *Server) listCalls(c echo.Context) error {
+	method := c.QueryParam("method")
+
+	s.mu.Lock()
+	calls := make([]Call, 0, len(s.calls))
+	for _, call := range s.calls {
+		if method == "" || call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	s.mu.Unlock()
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"calls": calls,
+	})
+}
+
+// clearCalls 記録を全て消す
+func (s *Server) clearCalls(c echo.Context) error {
+	s.mu.Lock()
+	s.calls = nil
+	s.mu.Unlock()
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"status": "success",
+	})
+}
+
+// issueTS 新しいメッセージの ts を払い出す
+func (s *Server) issueTS() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextTS++
+	return fmt.Sprintf("%d.000100", s.nextTS)
+}
+
+// record 呼び出しを記録する
+func (s *Server) record(method string, params map[string]interface{}, channel, ts string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	s.calls = append(s.calls, Call{
+		ID:         s.nextID,
+		Method:     method,
+		Params:     params,
+		Channel:    channel,
+		TS:         ts,
+		ReceivedAt: time.Now(),
+	})
+}
+
+// readParams フォーム・クエリ・JSONボディのいずれからもパラメータを読む
+// blocks や view のようなJSON文字列の値はデコードして記録する
+func readParams(c echo.Context) (map[string]interface{}, error) {
+	params := map[string]interface{}{}
+	req := c.Request()
+
+	if strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		if len(body) > 0 {
+			if err := json.Unmarshal(body, &params); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		if err := req.ParseForm(); err != nil {
+			return nil, err
+		}
+		for key, values := range req.Form {
+			if len(values) == 0 {
+				continue
+			}
+			var decoded interface{}
+			if (key == "blocks" || key == "attachments" || key == "view") && json.Unmarshal([]byte(values[0]), &decoded) == nil {
+				params[key] = decoded
+				continue
+			}
+			params[key] = values[0]
+		}
+	}
+
+	delete(params, "token") // トークンは記録しない
+	return params, nil
+}
+
+// stringParam パラメータを文字列として取り出す
+func stringParam(params map[string]interface{}, key string) string {
+	v, _ := params[key].(string)
+	return v
+}
+
+// slackError Slack APIと同じ形式のエラーを返す（HTTPステータスは200）
+func slackError(c echo.Context, code string) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ok":    false,
+		"error": code,
+	})
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultAuditLimit 件数指定が無いときに返す最大件数
+const defaultAuditLimit = 100
+
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// GetAuditLog 管理操作の記録を新しい順に取得（?actor_id=3&limit=50）
+func (h *AuditHandler) GetAuditLog(c echo.Context) error {
+	filter := model.AuditLogFilter{Limit: defaultAuditLimit}
+
+	params := []struct {
+		name string
+		dst  *int
+	}{
+		{"actor_id", &filter.ActorUserID},
+		{"limit", &filter.Limit},
+	}
+	for _, p := range params {
+		v := c.QueryParam(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return badRequest(c, "Invalid "+p.name)
+		}
+		*p.dst = n
+	}
+
+	logs, err := h.auditService.List(filter)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"audit_log": logs,
+	})
+}
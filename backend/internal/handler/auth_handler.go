@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"seeft-slack-notification/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// loginStateCookie ログイン開始時の state を入れる cookie
+const loginStateCookie = "seeft_login_state"
+
+type AuthHandler struct {
+	authService *service.AuthService
+	successURL  string
+}
+
+func NewAuthHandler(authService *service.AuthService, successURL string) *AuthHandler {
+	return &AuthHandler{
+		authService: authService,
+		successURL:  successURL,
+	}
+}
+
+// SlackLogin Sign in with Slack のログイン画面にリダイレクトする
+func (h *AuthHandler) SlackLogin(c echo.Context) error {
+	authURL, state, err := h.authService.LoginURL(c.Request().Context())
+	if err != nil {
+		log.Printf("Failed to start slack login: %v", err)
+		return errorJSON(c, http.StatusServiceUnavailable, CodeServiceUnavailable, "slack login is not available")
+	}
+
+	c.SetCookie(&http.Cookie{
+		Name:     loginStateCookie,
+		Value:    state,
+		Path:     "/api/auth",
+		Expires:  time.Now().Add(10 * time.Minute),
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode, // Slack からのリダイレクト (GET) では送られる
+	})
+
+	return c.Redirect(http.StatusFound, authURL)
+}
+
+// SlackCallback Slack からのリダイレクトを受け、APIトークンを発行する
+// AUTH_SUCCESS_URL があればトークンをフラグメント(#token=...)に付けてフロントエンドへ戻す
+func (h *AuthHandler) SlackCallback(c echo.Context) error {
+	if e := c.QueryParam("error"); e != "" {
+		return errorJSON(c, http.StatusUnauthorized, CodeUnauthenticated, "slack login was cancelled: "+e)
+	}
+
+	var stateCookie string
+	if cookie, err := c.Cookie(loginStateCookie); err == nil {
+		stateCookie = cookie.Value
+	}
+	// state は1回きりなので消しておく
+	c.SetCookie(&http.Cookie{Name: loginStateCookie, Path: "/api/auth", MaxAge: -1})
+
+	token, err := h.authService.CompleteLogin(c.Request().Context(), c.QueryParam("code"), c.QueryParam("state"), stateCookie)
+	switch {
+	case errors.Is(err, service.ErrInvalidLoginState), errors.Is(err, service.ErrUnknownSlackUser),
+		errors.Is(err, service.ErrUserDeactivated):
+		return respondError(c, err)
+	case err != nil:
+		log.Printf("Failed to complete slack login: %v", err)
+		return errorJSON(c, http.StatusBadGateway, CodeUpstreamError, "failed to sign in with slack")
+	}
+
+	if h.successURL == "" {
+		return c.JSON(http.StatusOK, token)
+	}
+
+	// フラグメントはサーバーに送られないので、アクセスログ等にトークンが残らない
+	fragment := url.Values{}
+	fragment.Set("token", token.Token)
+	fragment.Set("expires_at", token.ExpiresAt.UTC().Format(time.RFC3339))
+	return c.Redirect(http.StatusFound, h.successURL+"#"+fragment.Encode())
+}
+
+// Me ログイン中のユーザーを返す
+func (h *AuthHandler) Me(c echo.Context) error {
+	return c.JSON(http.StatusOK, principalFrom(c))
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// principalKey 認証済みの利用者を echo.Context に入れるキー
+const principalKey = "principal"
+
+// auditChangesKey ハンドラが監査ログに残す変更内容（変更前後の値など）を echo.Context に入れるキー
+const auditChangesKey = "audit_changes"
+
+// maxAuditBodySize 監査ログにそのまま残すリクエストボディの上限（超える場合はサイズのみ記録）
+const maxAuditBodySize = 8 * 1024
+
+// RequireAuth APIトークンを検証し、利用者を echo.Context に入れるミドルウェア
+// トークンは Authorization: Bearer で渡す
+func RequireAuth(authService *service.AuthService) echo.MiddlewareFunc {
+	return requireAuth(authService, false)
+}
+
+// RequireStreamAuth RequireAuth と同じだが、ヘッダーを付けられない EventSource 用に ?access_token= も受け付ける
+// URLのトークンはログなどに残りやすいので、SSEのルートだけに使う
+func RequireStreamAuth(authService *service.AuthService) echo.MiddlewareFunc {
+	return requireAuth(authService, true)
+}
+
+func requireAuth(authService *service.AuthService, allowQueryToken bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var token string
+			if allowQueryToken {
+				token = c.QueryParam("access_token")
+			}
+			if auth := c.Request().Header.Get(echo.HeaderAuthorization); auth != "" {
+				scheme, value, _ := strings.Cut(auth, " ")
+				if !strings.EqualFold(scheme, "Bearer") {
+					return unauthorized(c)
+				}
+				token = strings.TrimSpace(value)
+			}
+
+			principal, err := authService.Authenticate(token)
+			if errors.Is(err, service.ErrUnauthenticated) {
+				return unauthorized(c)
+			}
+			if err != nil {
+				return respondError(c, err)
+			}
+
+			c.Set(principalKey, principal)
+			return next(c)
+		}
+	}
+}
+
+// gasKeyHeader GAS（スプレッドシート）が共有キーを渡すヘッダー
+const gasKeyHeader = "X-GAS-Key"
+
+// RequireGASOrAdmin GAS向けのAPIを、共有キーを持つGASか管理者だけに通すミドルウェア
+// gasKey が空なら共有キーは受け付けず、管理者のトークンのみ通す
+func RequireGASOrAdmin(gasKey string, authService *service.AuthService) echo.MiddlewareFunc {
+	requireAdmin := RequireAuth(authService)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		admin := requireAdmin(RequireRole(model.RoleAdmin)(next))
+		return func(c echo.Context) error {
+			if key := c.Request().Header.Get(gasKeyHeader); key != "" {
+				if gasKey == "" || subtle.ConstantTimeCompare([]byte(key), []byte(gasKey)) != 1 {
+					return unauthorized(c)
+				}
+				return next(c)
+			}
+			return admin(c)
+		}
+	}
+}
+
+// RequireRole 指定した権限のいずれかを持つ利用者だけを通すミドルウェア（RequireAuth の後に使う）
+func RequireRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal := principalFrom(c)
+			if principal == nil {
+				return unauthorized(c)
+			}
+			if !principal.HasRole(roles...) {
+				return respondError(c, service.ErrForbidden)
+			}
+			return next(c)
+		}
+	}
+}
+
+// AuditPrivileged 権限が必要なルートでの変更操作（GET以外）を、操作した利用者と結果付きで記録するミドルウェア
+// 権限不足で拒否された操作も記録する
+func AuditPrivileged(auditService *service.AuditService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == http.MethodOptions {
+				return next(c)
+			}
+
+			// ハンドラでも読めるよう、読んだボディを戻しておく
+			var body []byte
+			if req.Body != nil {
+				var err error
+				if body, err = io.ReadAll(req.Body); err != nil {
+					return badRequest(c, "Invalid request body")
+				}
+				req.Body = io.NopCloser(bytes.NewReader(body))
+			}
+
+			err := next(c)
+
+			status := c.Response().Status
+			var he *echo.HTTPError
+			if errors.As(err, &he) {
+				status = he.Code
+			}
+
+			detail := map[string]interface{}{}
+			if len(req.URL.RawQuery) > 0 {
+				detail["query"] = req.URL.Query()
+			}
+			switch {
+			case len(body) == 0:
+			case len(body) > maxAuditBodySize:
+				detail["body_size"] = len(body)
+			case json.Valid(body):
+				detail["body"] = json.RawMessage(body)
+			default:
+				detail["body"] = string(body)
+			}
+
+			if changes := c.Get(auditChangesKey); changes != nil {
+				detail["changes"] = changes
+			}
+
+			auditService.Record(principalFrom(c), req.Method+" "+c.Path(), req.URL.Path, status, detail)
+			return err
+		}
+	}
+}
+
+// setAuditChanges AuditPrivileged が記録する監査ログに、変更内容を加える
+func setAuditChanges(c echo.Context, changes interface{}) {
+	c.Set(auditChangesKey, changes)
+}
+
+// principalFrom RequireAuth が入れた利用者を取り出す
+func principalFrom(c echo.Context) *model.Principal {
+	p, _ := c.Get(principalKey).(*model.Principal)
+	return p
+}
+
+var (
+	errInvalidUserID = errors.New("invalid user id")
+	errOtherUser     = errors.New("cannot access another user's data")
+)
+
+// resolveUserID パスの :id を利用者のIDとして解決する
+// "me" なら自分、数値なら自分のIDと一致する場合のみ許可する
+func resolveUserID(c echo.Context) (int, error) {
+	principal := principalFrom(c)
+
+	id := c.Param("id")
+	if id == "me" {
+		return principal.UserID, nil
+	}
+
+	userID, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, errInvalidUserID
+	}
+	if userID != principal.UserID {
+		return 0, errOtherUser
+	}
+	return userID, nil
+}
+
+// unauthorized 401 を返す
+func unauthorized(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="api"`)
+	return respondError(c, service.ErrUnauthenticated)
+}
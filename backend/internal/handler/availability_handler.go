@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AvailabilityHandler struct {
+	availabilityService *service.AvailabilityService
+}
+
+func NewAvailabilityHandler(availabilityService *service.AvailabilityService) *AvailabilityHandler {
+	return &AvailabilityHandler{
+		availabilityService: availabilityService,
+	}
+}
+
+// GetAvailability 自分が申告したNGを取得（?year_id= で年度を絞り込む）
+func (h *AvailabilityHandler) GetAvailability(c echo.Context) error {
+	userID, err := resolveUserID(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	yearID, err := optionalYearID(c)
+	if err != nil {
+		return badRequest(c, err.Error())
+	}
+
+	availability, err := h.availabilityService.Get(userID, yearID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, availability)
+}
+
+// SubmitAvailability ある年度のNGを申告する（送った内容で置き換える）
+func (h *AvailabilityHandler) SubmitAvailability(c echo.Context) error {
+	userID, err := resolveUserID(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	var req model.AvailabilityRequest
+	if err := c.Bind(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+
+	availability, err := h.availabilityService.Submit(userID, req)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, availability)
+}
+
+// ListAvailability 全員のNGの一覧（?year_id=&user_id=&date=&weather=）
+func (h *AvailabilityHandler) ListAvailability(c echo.Context) error {
+	filter, err := parseAvailabilityFilter(c)
+	if err != nil {
+		return badRequest(c, err.Error())
+	}
+
+	ranges, err := h.availabilityService.List(filter)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ranges": ranges,
+	})
+}
+
+// GetNGSlots NGを枠ごとのセルに展開したもの（GASがシートに 'NG' を書き込む用）
+func (h *AvailabilityHandler) GetNGSlots(c echo.Context) error {
+	filter, err := parseAvailabilityFilter(c)
+	if err != nil {
+		return badRequest(c, err.Error())
+	}
+
+	slots, err := h.availabilityService.Slots(filter)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"slots": slots,
+	})
+}
+
+// parseAvailabilityFilter NGの一覧の絞り込み条件を読む
+func parseAvailabilityFilter(c echo.Context) (model.AvailabilityFilter, error) {
+	yearID, err := optionalYearID(c)
+	if err != nil {
+		return model.AvailabilityFilter{}, err
+	}
+
+	filter := model.AvailabilityFilter{
+		YearID:  yearID,
+		Date:    c.QueryParam("date"),
+		Weather: c.QueryParam("weather"),
+	}
+	if v := c.QueryParam("user_id"); v != "" {
+		if filter.UserID, err = strconv.Atoi(v); err != nil {
+			return model.AvailabilityFilter{}, errInvalidUserID
+		}
+	}
+	return filter, nil
+}
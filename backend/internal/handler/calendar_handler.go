@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CalendarHandler struct {
+	calendarService *service.CalendarService
+}
+
+func NewCalendarHandler(calendarService *service.CalendarService) *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: calendarService,
+	}
+}
+
+// IssueToken カレンダー購読用のURLを発行する（再発行すると以前のURLは使えなくなる）
+func (h *CalendarHandler) IssueToken(c echo.Context) error {
+	userID, err := resolveUserID(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	token, err := h.calendarService.IssueToken(userID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	feedURL := fmt.Sprintf("%s://%s/api/users/%d/calendar.ics?token=%s", c.Scheme(), c.Request().Host, userID, url.QueryEscape(token))
+	return c.JSON(http.StatusOK, model.CalendarToken{
+		UserID: userID,
+		Token:  token,
+		URL:    feedURL,
+	})
+}
+
+// GetFeed ユーザーのシフトを iCalendar 形式で返す（?token=...）
+// カレンダーアプリはヘッダーを付けられないので、APIトークンではなくURLのトークンで認証する
+func (h *CalendarHandler) GetFeed(c echo.Context) error {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return badRequest(c, "invalid user id")
+	}
+
+	feed, err := h.calendarService.Feed(userID, c.QueryParam("token"))
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	c.Response().Header().Set(echo.HeaderCacheControl, "private, no-cache")
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", feed)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultDeliveryLimit 件数指定が無いときに返す最大件数
+const defaultDeliveryLimit = 100
+
+type DeliveryHandler struct {
+	deliveryService *service.DeliveryService
+}
+
+func NewDeliveryHandler(deliveryService *service.DeliveryService) *DeliveryHandler {
+	return &DeliveryHandler{
+		deliveryService: deliveryService,
+	}
+}
+
+// GetDeliveries 通知の配信記録を取得（?user_id=3&shift_id=10&sync_id=5&status=failed&limit=50）
+func (h *DeliveryHandler) GetDeliveries(c echo.Context) error {
+	filter := model.DeliveryFilter{
+		Status: c.QueryParam("status"),
+		Limit:  defaultDeliveryLimit,
+	}
+
+	params := []struct {
+		name string
+		dst  *int
+	}{
+		{"user_id", &filter.UserID},
+		{"shift_id", &filter.ShiftID},
+		{"sync_id", &filter.SyncID},
+		{"limit", &filter.Limit},
+	}
+	for _, p := range params {
+		v := c.QueryParam(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return badRequest(c, "Invalid "+p.name)
+		}
+		*p.dst = n
+	}
+
+	switch filter.Status {
+	case "", model.DeliveryStatusQueued, model.DeliveryStatusSent, model.DeliveryStatusFailed, model.DeliveryStatusSuppressed:
+	default:
+		return badRequest(c, "Invalid status")
+	}
+
+	deliveries, err := h.deliveryService.List(filter)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"deliveries": deliveries,
+	})
+}
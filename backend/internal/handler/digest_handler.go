@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"seeft-slack-notification/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type DigestHandler struct {
+	digestService *service.ChannelDigestService
+}
+
+func NewDigestHandler(digestService *service.ChannelDigestService) *DigestHandler {
+	return &DigestHandler{
+		digestService: digestService,
+	}
+}
+
+// GetChangeDigest 指定期間の変更まとめを取得（?from=...&to=... RFC3339, 省略時は直近24時間）
+func (h *DigestHandler) GetChangeDigest(c echo.Context) error {
+	from, to, err := parseDigestWindow(c)
+	if err != nil {
+		return badRequest(c, err.Error())
+	}
+
+	digest, err := h.digestService.Build(from, to)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, digest)
+}
+
+// PostChangeDigest 指定期間の変更まとめを作成し、運営チャンネルに投稿する
+func (h *DigestHandler) PostChangeDigest(c echo.Context) error {
+	from, to, err := parseDigestWindow(c)
+	if err != nil {
+		return badRequest(c, err.Error())
+	}
+
+	digest, err := h.digestService.Build(from, to)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	if err := h.digestService.Post(c.Request().Context(), digest); err != nil {
+		log.Printf("Failed to post change digest: %v", err)
+		return errorJSON(c, http.StatusBadGateway, CodeUpstreamError, "failed to post to slack")
+	}
+
+	return c.JSON(http.StatusOK, digest)
+}
+
+// parseDigestWindow クエリパラメータから期間を読み取る
+func parseDigestWindow(c echo.Context) (time.Time, time.Time, error) {
+	to := time.Now()
+	if v := c.QueryParam("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid to (RFC3339)")
+		}
+		to = t
+	}
+
+	from := to.Add(-24 * time.Hour)
+	if v := c.QueryParam("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid from (RFC3339)")
+		}
+		from = t
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, errors.New("from must be before to")
+	}
+
+	return from, to, nil
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"seeft-slack-notification/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// エラーコード（クライアントはメッセージではなくこちらで分岐する）
+const (
+	CodeInvalidRequest     = "invalid_request"     // パラメータ・ボディの形式が不正
+	CodeValidationFailed   = "validation_failed"   // 値の内容が不正
+	CodeUnauthenticated    = "unauthenticated"     // ログインが必要
+	CodeForbidden          = "forbidden"           // 権限が足りない
+	CodeNotFound           = "not_found"           // 対象が存在しない
+	CodeMethodNotAllowed   = "method_not_allowed"  // メソッドが違う
+	CodeConflict           = "conflict"            // 既存のデータと矛盾する
+	CodeUpstreamError      = "upstream_error"      // Slack などの外部サービスのエラー
+	CodeServiceUnavailable = "service_unavailable" // 一時的に使えない
+	CodeInternal           = "internal_error"      // サーバー内部のエラー
+)
+
+// ErrorResponse エラーレスポンス {"error": {"code": "not_found", "message": "shift not found"}}
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody エラーの内容
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// errorKinds サービス・リポジトリのエラー -> ステータスとコード（上から順に errors.Is で照合する）
+var errorKinds = []struct {
+	target error
+	status int
+	code   string
+}{
+	{service.ErrUnauthenticated, http.StatusUnauthorized, CodeUnauthenticated},
+	{service.ErrForbidden, http.StatusForbidden, CodeForbidden},
+	{service.ErrNotShiftOwner, http.StatusForbidden, CodeForbidden},
+	{service.ErrUnknownSlackUser, http.StatusForbidden, CodeForbidden},
+	{service.ErrUserDeactivated, http.StatusForbidden, CodeForbidden},
+	{errOtherUser, http.StatusForbidden, CodeForbidden},
+	{service.ErrNotFound, http.StatusNotFound, CodeNotFound},
+	{service.ErrInvalidCalendarToken, http.StatusNotFound, CodeNotFound},
+	{service.ErrConflict, http.StatusConflict, CodeConflict},
+	{service.ErrInvalidInput, http.StatusBadRequest, CodeValidationFailed},
+	{service.ErrInvalidCursor, http.StatusBadRequest, CodeValidationFailed},
+	{service.ErrInvalidLoginState, http.StatusBadRequest, CodeValidationFailed},
+	{errInvalidUserID, http.StatusBadRequest, CodeInvalidRequest},
+}
+
+// errorJSON エラーレスポンスを返す
+func errorJSON(c echo.Context, status int, code, message string) error {
+	return c.JSON(status, ErrorResponse{Error: ErrorBody{Code: code, Message: message}})
+}
+
+// badRequest パラメータ・ボディの形式が不正（400）
+func badRequest(c echo.Context, message string) error {
+	return errorJSON(c, http.StatusBadRequest, CodeInvalidRequest, message)
+}
+
+// respondError エラーの種類に応じたステータスとコードで返す
+// 種類の分からないエラー（DBのエラーなど）は内容をログにだけ残し、利用者には詳細を返さない
+func respondError(c echo.Context, err error) error {
+	for _, k := range errorKinds {
+		if errors.Is(err, k.target) {
+			return errorJSON(c, k.status, k.code, err.Error())
+		}
+	}
+
+	log.Printf("Error: %s %s: %v", c.Request().Method, c.Path(), err)
+	return errorJSON(c, http.StatusInternalServerError, CodeInternal, "internal server error")
+}
+
+// HTTPErrorHandler echo が返すエラー（存在しないルート・405・ミドルウェアのエラーなど）も同じ形で返す
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	var he *echo.HTTPError
+	if !errors.As(err, &he) {
+		if err := respondError(c, err); err != nil {
+			log.Printf("Failed to write error response: %v", err)
+		}
+		return
+	}
+
+	message := http.StatusText(he.Code)
+	if m, ok := he.Message.(string); ok && he.Code < http.StatusInternalServerError {
+		message = m
+	}
+	if he.Code >= http.StatusInternalServerError {
+		log.Printf("Error: %s %s: %v", c.Request().Method, c.Path(), err)
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(he.Code)
+	} else {
+		err = errorJSON(c, he.Code, codeForStatus(he.Code), message)
+	}
+	if err != nil {
+		log.Printf("Failed to write error response: %v", err)
+	}
+}
+
+// codeForStatus ステータスコードに対応するエラーコード
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusBadGateway:
+		return CodeUpstreamError
+	case http.StatusServiceUnavailable:
+		return CodeServiceUnavailable
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeInvalidRequest
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type EscalationHandler struct {
+	escalationService *service.EscalationService
+}
+
+func NewEscalationHandler(escalationService *service.EscalationService) *EscalationHandler {
+	return &EscalationHandler{
+		escalationService: escalationService,
+	}
+}
+
+// GetUnacknowledged 未読のままの開催直前の変更を、シフトの開始が近い順に取得（?user_id=&task=）
+func (h *EscalationHandler) GetUnacknowledged(c echo.Context) error {
+	filter := model.UnacknowledgedFilter{TaskName: c.QueryParam("task")}
+	if v := c.QueryParam("user_id"); v != "" {
+		userID, err := strconv.Atoi(v)
+		if err != nil {
+			return badRequest(c, "invalid user_id")
+		}
+		filter.UserID = userID
+	}
+
+	changes, err := h.escalationService.Outstanding(filter, time.Now())
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"changes": changes,
+	})
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"seeft-slack-notification/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// sseBatchSize 1回のクエリで送る変更の件数（溜まっている分は繰り返し送る）
+const sseBatchSize = 100
+
+type EventHandler struct {
+	notificationService *service.NotificationService
+	readService         *service.ReadService
+	broker              *service.ChangeBroker
+	heartbeat           time.Duration
+}
+
+func NewEventHandler(
+	notificationService *service.NotificationService,
+	readService *service.ReadService,
+	broker *service.ChangeBroker,
+	heartbeat time.Duration,
+) *EventHandler {
+	return &EventHandler{
+		notificationService: notificationService,
+		readService:         readService,
+		broker:              broker,
+		heartbeat:           heartbeat,
+	}
+}
+
+// StreamEvents ログイン中のユーザーのシフトの変更を Server-Sent Events で配信する
+// 各変更は "change" イベント（id は action_log のID）、続けて "unread_count" イベントを送る
+// 再接続時は Last-Event-ID ヘッダー（または ?last_event_id=）以降の変更から再開する
+func (h *EventHandler) StreamEvents(c echo.Context) error {
+	userID := principalFrom(c).UserID
+
+	lastEventID := c.Request().Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.QueryParam("last_event_id")
+	}
+
+	var lastID int
+	var err error
+	if lastEventID != "" {
+		lastID, err = strconv.Atoi(lastEventID)
+		if err != nil || lastID < 0 {
+			return badRequest(c, "invalid Last-Event-ID")
+		}
+	}
+
+	// 取りこぼさないよう、最新IDを調べる前に購読を始める
+	notify, unsubscribe := h.broker.Subscribe(userID)
+	defer unsubscribe()
+
+	if lastEventID == "" {
+		// 初回接続はこれから起きる変更だけを送る
+		if lastID, err = h.notificationService.LatestID(userID); err != nil {
+			return respondError(c, err)
+		}
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no") // nginx などのバッファリングを止める
+	res.WriteHeader(http.StatusOK)
+
+	// 切断されたら3秒後に再接続してもらう
+	fmt.Fprintf(res, "retry: 3000\n\n")
+	if lastEventID != "" {
+		if lastID, err = h.sendChanges(res, userID, lastID); err != nil {
+			return nil
+		}
+	}
+	res.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	ctx := c.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-h.broker.Done():
+			return nil
+		case <-ticker.C:
+			// コメント行は EventSource に無視されるが、接続を生かしておける
+			if _, err := fmt.Fprintf(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case <-notify:
+			if lastID, err = h.sendChanges(res, userID, lastID); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+// sendChanges lastID より後の変更を全て送り、未読件数を送る。送った最後のIDを返す
+// 変更が無くても未読件数は送る（他の画面で既読にした場合も通知される）
+func (h *EventHandler) sendChanges(res *echo.Response, userID, lastID int) (int, error) {
+	for {
+		changes, err := h.notificationService.Since(userID, lastID, sseBatchSize)
+		if err != nil {
+			return lastID, err
+		}
+		for _, change := range changes {
+			if err := writeEvent(res, strconv.Itoa(change.ID), "change", change); err != nil {
+				return lastID, err
+			}
+			lastID = change.ID
+		}
+		if len(changes) < sseBatchSize {
+			break
+		}
+	}
+
+	count, err := h.readService.UnreadCount(userID)
+	if err != nil {
+		return lastID, err
+	}
+	// id を付けないので、再接続時の Last-Event-ID は変わらない
+	return lastID, writeEvent(res, "", "unread_count", map[string]int{"unread_count": count})
+}
+
+// writeEvent SSEのイベントを1つ書き込む
+func writeEvent(res *echo.Response, id, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		if _, err := fmt.Fprintf(res, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event, payload)
+	return err
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"seeft-slack-notification/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type HistoryHandler struct {
+	historyService *service.HistoryService
+}
+
+func NewHistoryHandler(historyService *service.HistoryService) *HistoryHandler {
+	return &HistoryHandler{
+		historyService: historyService,
+	}
+}
+
+// GetShiftHistory シフトの変更履歴を古い順に取得（本人・admin・そのタスクの lead のみ。削除済みのシフトも可）
+func (h *HistoryHandler) GetShiftHistory(c echo.Context) error {
+	shiftID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return badRequest(c, "invalid shift id")
+	}
+
+	history, err := h.historyService.ShiftHistory(principalFrom(c), shiftID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, history)
+}
@@ -3,49 +3,49 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
-	"github.com/labstack/echo/v4"
 	"seeft-slack-notification/internal/model"
-	"seeft-slack-notification/internal/repository"
+	"seeft-slack-notification/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultNotificationLimit 件数指定が無いときに返す最大件数
+const defaultNotificationLimit = 100
+
+// タイムラインの1ページの件数
+const (
+	defaultTimelineLimit = 50
+	maxTimelineLimit     = 200
 )
 
 type NotificationHandler struct {
-	notificationRepo *repository.NotificationRepository
+	notificationService *service.NotificationService
 }
 
-func NewNotificationHandler(notificationRepo *repository.NotificationRepository) *NotificationHandler {
+func NewNotificationHandler(notificationService *service.NotificationService) *NotificationHandler {
 	return &NotificationHandler{
-		notificationRepo: notificationRepo,
+		notificationService: notificationService,
 	}
 }
 
-// GetNotifications 未読通知一覧を取得
+// GetNotifications ログイン中のユーザーの通知一覧を取得（?unread=true&limit=50）
 func (h *NotificationHandler) GetNotifications(c echo.Context) error {
-	// クエリパラメータからuser_idを取得（実際の実装では認証から取得）
-	userIDStr := c.QueryParam("user_id")
-	if userIDStr == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "user_id is required",
-		})
-	}
+	userID := principalFrom(c).UserID
 
-	userID, err := strconv.Atoi(userIDStr)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "invalid user_id",
-		})
+	var err error
+	limit := defaultNotificationLimit
+	if v := c.QueryParam("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return badRequest(c, "invalid limit")
+		}
 	}
 
-	notifications, err := h.notificationRepo.GetUnreadByUserID(userID)
+	notifications, err := h.notificationService.List(userID, c.QueryParam("unread") == "true", limit)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
-		})
-	}
-
-	// nilの場合は空のスライスに変換して、必ず空の配列[]を返すようにする
-	if notifications == nil {
-		notifications = []model.NotificationResponse{}
+		return respondError(c, err)
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
@@ -53,3 +53,45 @@ func (h *NotificationHandler) GetNotifications(c echo.Context) error {
 	})
 }
 
+// GetTimeline ユーザーの全シフトの変更を新しい順に取得（削除も含む）
+// ?cursor=...&limit=50&date=1日目&weather=晴れ&action=UPDATE,DELETE&unread=true
+func (h *NotificationHandler) GetTimeline(c echo.Context) error {
+	userID, err := resolveUserID(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	filter := model.TimelineFilter{
+		UserID:     userID,
+		UnreadOnly: c.QueryParam("unread") == "true",
+		Date:       c.QueryParam("date"),
+		Weather:    c.QueryParam("weather"),
+		Limit:      defaultTimelineLimit,
+	}
+
+	if v := c.QueryParam("limit"); v != "" {
+		filter.Limit, err = strconv.Atoi(v)
+		if err != nil || filter.Limit <= 0 || filter.Limit > maxTimelineLimit {
+			return badRequest(c, "limit must be between 1 and "+strconv.Itoa(maxTimelineLimit))
+		}
+	}
+
+	if v := c.QueryParam("action"); v != "" {
+		for _, action := range strings.Split(v, ",") {
+			action = strings.ToUpper(strings.TrimSpace(action))
+			switch action {
+			case "CREATE", "UPDATE", "DELETE":
+				filter.ActionTypes = append(filter.ActionTypes, action)
+			default:
+				return badRequest(c, "invalid action: "+action)
+			}
+		}
+	}
+
+	page, err := h.notificationService.Timeline(filter, c.QueryParam("cursor"))
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,14 @@
+package handler
+
+import (
+	"net/http"
+
+	"seeft-slack-notification/internal/apidoc"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetOpenAPI API の OpenAPI ドキュメント（YAML）を返す
+func GetOpenAPI(c echo.Context) error {
+	return c.Blob(http.StatusOK, "application/yaml", apidoc.Spec)
+}
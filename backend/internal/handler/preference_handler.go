@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PreferenceHandler struct {
+	prefService *service.PreferenceService
+}
+
+func NewPreferenceHandler(prefService *service.PreferenceService) *PreferenceHandler {
+	return &PreferenceHandler{
+		prefService: prefService,
+	}
+}
+
+// GetPreference ユーザーの通知設定を取得
+func (h *PreferenceHandler) GetPreference(c echo.Context) error {
+	userID, err := resolveUserID(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	pref, err := h.prefService.Get(userID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, pref)
+}
+
+// UpdatePreference ユーザーの通知設定を更新
+func (h *PreferenceHandler) UpdatePreference(c echo.Context) error {
+	userID, err := resolveUserID(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	var req model.NotificationPreferenceRequest
+	if err := c.Bind(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+
+	pref, err := h.prefService.Update(userID, req)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, pref)
+}
@@ -1,57 +1,87 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/service"
+
 	"github.com/labstack/echo/v4"
-	"seeft-slack-notification/internal/repository"
 )
 
+// maxMarkReadShifts 1回のまとめ既読で指定できるシフト数
+const maxMarkReadShifts = 500
+
 type ReadHandler struct {
-	notificationRepo *repository.NotificationRepository
+	readService *service.ReadService
 }
 
-func NewReadHandler(notificationRepo *repository.NotificationRepository) *ReadHandler {
+func NewReadHandler(readService *service.ReadService) *ReadHandler {
 	return &ReadHandler{
-		notificationRepo: notificationRepo,
+		readService: readService,
 	}
 }
 
-// MarkAsRead 通知を既読にする
-func (h *ReadHandler) MarkAsRead(c echo.Context) error {
-	// URLパラメータからnotification_idを取得
-	notificationIDStr := c.Param("id")
-	notificationID, err := strconv.Atoi(notificationIDStr)
+// MarkShiftRead ログイン中のユーザーのシフトを既読にする
+func (h *ReadHandler) MarkShiftRead(c echo.Context) error {
+	shiftID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "invalid notification id",
-		})
+		return badRequest(c, "invalid shift id")
 	}
 
-	// クエリパラメータからuser_idを取得（実際の実装では認証から取得）
-	userIDStr := c.QueryParam("user_id")
-	if userIDStr == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "user_id is required",
-		})
+	readAt, err := h.readService.MarkRead(principalFrom(c).UserID, shiftID)
+	if err != nil {
+		return respondError(c, err)
 	}
 
-	userID, err := strconv.Atoi(userIDStr)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "invalid user_id",
-		})
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"read_at": readAt,
+	})
+}
+
+// MarkShiftsRead ログイン中のユーザーのシフトをまとめて既読にする
+// {"shift_ids": [1, 2]} または {"before": "2024-01-01T12:00:00Z"}
+func (h *ReadHandler) MarkShiftsRead(c echo.Context) error {
+	var req model.MarkReadRequest
+	if err := c.Bind(&req); err != nil {
+		return badRequest(c, "Invalid request body")
 	}
 
-	if err := h.notificationRepo.MarkAsRead(notificationID, userID); err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
-		})
+	if (len(req.ShiftIDs) == 0) == (req.Before == nil) {
+		return badRequest(c, "either shift_ids or before is required")
+	}
+	if len(req.ShiftIDs) > maxMarkReadShifts {
+		return badRequest(c, fmt.Sprintf("shift_ids must not exceed %d", maxMarkReadShifts))
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{
-		"status": "success",
+	updated, err := h.readService.MarkReadBulk(principalFrom(c).UserID, req)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"updated": updated,
 	})
 }
 
+// GetUnreadCount 未読の変更の件数を取得
+func (h *ReadHandler) GetUnreadCount(c echo.Context) error {
+	userID, err := resolveUserID(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	count, err := h.readService.UnreadCount(userID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]int{
+		"user_id":      userID,
+		"unread_count": count,
+	})
+}
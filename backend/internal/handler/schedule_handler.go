@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// シフト検索の件数
+const (
+	defaultShiftSearchLimit = 50
+	maxShiftSearchLimit     = 200
+)
+
+type ScheduleHandler struct {
+	scheduleService *service.ScheduleService
+}
+
+func NewScheduleHandler(scheduleService *service.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{
+		scheduleService: scheduleService,
+	}
+}
+
+// GetGrid ユーザー × 時間枠のスケジュール表を取得
+// ?year_id=43&date=1日目&weather=晴れ&task=受付&group=1年（task / group は省略可）
+// 内容が変わっていなければ If-None-Match に対して 304 を返す
+func (h *ScheduleHandler) GetGrid(c echo.Context) error {
+	yearID, err := strconv.Atoi(c.QueryParam("year_id"))
+	if err != nil {
+		return badRequest(c, "year_id is required")
+	}
+
+	filter := model.ScheduleGridFilter{
+		YearID:  yearID,
+		Date:    c.QueryParam("date"),
+		Weather: c.QueryParam("weather"),
+		Task:    c.QueryParam("task"),
+		Group:   c.QueryParam("group"),
+	}
+	if filter.Date == "" || filter.Weather == "" {
+		return badRequest(c, "date and weather are required")
+	}
+
+	grid, err := h.scheduleService.Grid(filter)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	body, err := json.Marshal(grid)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	// シフトが変わるとレスポンスも変わるので、レスポンスのハッシュを ETag にする
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Response().Header().Set(echo.HeaderCacheControl, "private, no-cache")
+	c.Response().Header().Set("ETag", etag)
+	if etagMatches(c.Request().Header.Get("If-None-Match"), etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return c.JSONBlob(http.StatusOK, body)
+}
+
+// SearchShifts 有効なシフトを検索
+// ?user=山田&task=受付&task_match=prefix&date=1日目&weather=晴れ&from=09:00&to=12:00&year_id=43
+// &sort=date,-time,user&limit=50&offset=0
+func (h *ScheduleHandler) SearchShifts(c echo.Context) error {
+	filter := model.ShiftSearchFilter{
+		UserName:  strings.TrimSpace(c.QueryParam("user")),
+		Task:      strings.TrimSpace(c.QueryParam("task")),
+		TaskMatch: c.QueryParam("task_match"),
+		Date:      c.QueryParam("date"),
+		Weather:   c.QueryParam("weather"),
+		Limit:     defaultShiftSearchLimit,
+	}
+
+	switch filter.TaskMatch {
+	case "":
+		filter.TaskMatch = model.TaskMatchContains
+	case model.TaskMatchContains, model.TaskMatchPrefix:
+	default:
+		return badRequest(c, "task_match must be contains or prefix")
+	}
+
+	var err error
+	if filter.YearID, err = optionalYearID(c); err != nil {
+		return badRequest(c, err.Error())
+	}
+	if v := c.QueryParam("limit"); v != "" {
+		filter.Limit, err = strconv.Atoi(v)
+		if err != nil || filter.Limit <= 0 || filter.Limit > maxShiftSearchLimit {
+			return badRequest(c, "limit must be between 1 and "+strconv.Itoa(maxShiftSearchLimit))
+		}
+	}
+	if v := c.QueryParam("offset"); v != "" {
+		if filter.Offset, err = strconv.Atoi(v); err != nil || filter.Offset < 0 {
+			return badRequest(c, "Invalid offset")
+		}
+	}
+
+	// 時間の範囲は枠の開始時刻で指定する（to の枠は含まない）
+	if v := c.QueryParam("from"); v != "" {
+		if filter.FromTimeID, err = service.ClockToTimeID(v); err != nil {
+			return badRequest(c, "from: "+err.Error())
+		}
+	}
+	if v := c.QueryParam("to"); v != "" {
+		if filter.ToTimeID, err = service.ClockToTimeID(v); err != nil {
+			return badRequest(c, "to: "+err.Error())
+		}
+	}
+	if filter.FromTimeID != 0 && filter.ToTimeID != 0 && filter.FromTimeID >= filter.ToTimeID {
+		return badRequest(c, "from must be earlier than to")
+	}
+
+	// "-" を付けると降順
+	if v := c.QueryParam("sort"); v != "" {
+		for _, key := range strings.Split(v, ",") {
+			key = strings.TrimSpace(key)
+			sort := model.ShiftSort{Field: strings.TrimPrefix(key, "-"), Desc: strings.HasPrefix(key, "-")}
+			if !model.ValidShiftSortField(sort.Field) {
+				return badRequest(c, "invalid sort: "+key)
+			}
+			filter.Sort = append(filter.Sort, sort)
+		}
+	}
+
+	page, err := h.scheduleService.Search(filter)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+// GetTasks タスクごとの割り当て（枠数・人数・延べ時間）を取得（?year_id=43）
+func (h *ScheduleHandler) GetTasks(c echo.Context) error {
+	yearID, err := optionalYearID(c)
+	if err != nil {
+		return badRequest(c, err.Error())
+	}
+
+	tasks, err := h.scheduleService.Tasks(yearID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"tasks": tasks,
+	})
+}
+
+// GetTaskRoster タスクの担当表を日付・天気・時間帯ごとに取得（?year_id=43）
+func (h *ScheduleHandler) GetTaskRoster(c echo.Context) error {
+	// パスに %2F などが含まれると echo はエスケープされたままの値を渡すので戻す
+	taskName := c.Param("name")
+	if c.Request().URL.RawPath != "" {
+		unescaped, err := url.PathUnescape(taskName)
+		if err != nil {
+			return badRequest(c, "Invalid task name")
+		}
+		taskName = unescaped
+	}
+
+	yearID, err := optionalYearID(c)
+	if err != nil {
+		return badRequest(c, err.Error())
+	}
+
+	roster, err := h.scheduleService.Roster(taskName, yearID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, roster)
+}
+
+// optionalYearID ?year_id= を読む（省略時は0）
+func optionalYearID(c echo.Context) (int, error) {
+	v := c.QueryParam("year_id")
+	if v == "" {
+		return 0, nil
+	}
+	yearID, err := strconv.Atoi(v)
+	if err != nil || yearID <= 0 {
+		return 0, errors.New("Invalid year_id")
+	}
+	return yearID, nil
+}
+
+// etagMatches If-None-Match（カンマ区切り、弱いETagも可）に etag が含まれるか
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
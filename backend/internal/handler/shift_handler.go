@@ -27,24 +27,24 @@ func (h *ShiftHandler) UpdateShifts(c echo.Context) error {
 	// 1. JSONを受け取る
 	var req model.ShiftChangeRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "Invalid request body",
-		})
+		return badRequest(c, "Invalid request body")
 	}
 
 	// 2. サービスに「同期」を依頼する (ここでDB更新もログ保存も通知予約も全部やる！)
 	// ※ SyncShiftsの引数が []model.ShiftChange である前提です
-	if err := h.shiftService.SyncShifts(req.Changes); err != nil {
-		// エラーなら500を返す
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
-		})
+	result, err := h.shiftService.SyncShifts(req.Changes)
+	if err != nil {
+		return respondError(c, err)
 	}
 
 	// 3. 成功レスポンスを返す
 	// 通知の件数などは非同期処理になったため、即座には分かりません（「受け付けました」というスタンス）
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"status":  "success",
-		"message": "Shift sync started",
+		"status":       "success",
+		"message":      "Shift sync started",
+		"sync_id":      result.SyncID,      // 配信状況は GET /api/deliveries?sync_id= で確認できる
+		"ng_conflicts": result.NGConflicts, // NGを申告した枠に割り当てられているシフト
+		// シートへの書き戻し待ちの交換（その枠は同期していない。書き戻して ack してから同期し直す）
+		"pending_swap_ids": result.PendingSwapIDs,
 	})
 }
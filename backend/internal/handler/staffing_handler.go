@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type StaffingHandler struct {
+	staffingService *service.StaffingService
+}
+
+func NewStaffingHandler(staffingService *service.StaffingService) *StaffingHandler {
+	return &StaffingHandler{
+		staffingService: staffingService,
+	}
+}
+
+// GetRequirements 必要人数の一覧を取得（lead には担当タスクの分のみ）
+func (h *StaffingHandler) GetRequirements(c echo.Context) error {
+	reqs, err := h.staffingService.ListRequirements(principalFrom(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"requirements": reqs,
+	})
+}
+
+// ReplaceRequirements 必要人数をスプレッドシートの内容で置き換える
+func (h *StaffingHandler) ReplaceRequirements(c echo.Context) error {
+	var req model.StaffingRequirementRequest
+	if err := c.Bind(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+
+	if err := h.staffingService.ReplaceRequirements(req.Requirements); err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"count":  len(req.Requirements),
+	})
+}
+
+// GetCoverage 必要人数に対する配置状況を取得（?understaffed=true で不足枠のみ、lead には担当タスクのみ）
+func (h *StaffingHandler) GetCoverage(c echo.Context) error {
+	coverage, err := h.staffingService.Coverage(principalFrom(c), c.QueryParam("understaffed") == "true")
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"coverage": coverage,
+	})
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type SwapHandler struct {
+	swapService *service.SwapService
+}
+
+func NewSwapHandler(swapService *service.SwapService) *SwapHandler {
+	return &SwapHandler{
+		swapService: swapService,
+	}
+}
+
+// CreateSwap 自分のシフトと相手のシフトの交換を申請する
+func (h *SwapHandler) CreateSwap(c echo.Context) error {
+	var req model.ShiftSwapRequest
+	if err := c.Bind(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+
+	swap, err := h.swapService.Create(principalFrom(c), req)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, swap)
+}
+
+// GetSwaps 自分が申請した・申請された交換の一覧（?status=pending）
+func (h *SwapHandler) GetSwaps(c echo.Context) error {
+	swaps, err := h.swapService.List(principalFrom(c), c.QueryParam("status"))
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"shift_swaps": swaps,
+	})
+}
+
+// GetPendingApprovals リーダーの承認を待っている交換の一覧（lead は担当タスクの分のみ）
+func (h *SwapHandler) GetPendingApprovals(c echo.Context) error {
+	swaps, err := h.swapService.PendingApprovals(principalFrom(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"shift_swaps": swaps,
+	})
+}
+
+// AcceptSwap 交換の相手として承諾する
+func (h *SwapHandler) AcceptSwap(c echo.Context) error {
+	return h.act(c, h.swapService.Accept)
+}
+
+// DeclineSwap 交換の相手として断る
+func (h *SwapHandler) DeclineSwap(c echo.Context) error {
+	return h.act(c, h.swapService.Decline)
+}
+
+// CancelSwap 申請者として取り下げる
+func (h *SwapHandler) CancelSwap(c echo.Context) error {
+	return h.act(c, h.swapService.Cancel)
+}
+
+// ApproveSwap リーダーとして承認し、シフトに反映する
+func (h *SwapHandler) ApproveSwap(c echo.Context) error {
+	return h.act(c, h.swapService.Approve)
+}
+
+// RejectSwap リーダーとして却下する
+func (h *SwapHandler) RejectSwap(c echo.Context) error {
+	return h.act(c, h.swapService.Reject)
+}
+
+// act パスの :id の交換に操作をして、操作後の交換を返す
+func (h *SwapHandler) act(c echo.Context, fn func(*model.Principal, int) (*model.ShiftSwap, error)) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return badRequest(c, "invalid shift swap id")
+	}
+
+	swap, err := fn(principalFrom(c), id)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, swap)
+}
+
+// GetExports 承認済みでシートに書き戻していない交換（GAS向け）
+func (h *SwapHandler) GetExports(c echo.Context) error {
+	exports, err := h.swapService.Exports()
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"exports": exports,
+	})
+}
+
+// AckExports シートへの書き戻しが済んだ交換を記録する（GAS向け）
+// {"swap_ids": [1, 2]}
+func (h *SwapHandler) AckExports(c echo.Context) error {
+	var req model.ShiftSwapAckRequest
+	if err := c.Bind(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+
+	updated, err := h.swapService.AckExports(req.SwapIDs)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"updated": updated,
+	})
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TaskLeadHandler struct {
+	leadService *service.TaskLeadService
+}
+
+func NewTaskLeadHandler(leadService *service.TaskLeadService) *TaskLeadHandler {
+	return &TaskLeadHandler{
+		leadService: leadService,
+	}
+}
+
+// GetTaskLeads タスクリーダー一覧を取得（lead には担当タスクの分のみ）
+func (h *TaskLeadHandler) GetTaskLeads(c echo.Context) error {
+	leads, err := h.leadService.List(principalFrom(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"task_leads": leads,
+	})
+}
+
+// AddTaskLead タスクリーダーを登録（lead は担当タスクのみ）
+func (h *TaskLeadHandler) AddTaskLead(c echo.Context) error {
+	var req model.TaskLeadRequest
+	if err := c.Bind(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+
+	if err := h.leadService.Add(principalFrom(c), req); err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"status": "success",
+	})
+}
+
+// RemoveTaskLead タスクリーダーの登録を解除（?task_name=救護&user_id=3、lead は担当タスクのみ）
+func (h *TaskLeadHandler) RemoveTaskLead(c echo.Context) error {
+	taskName := c.QueryParam("task_name")
+	if taskName == "" {
+		return badRequest(c, "task_name is required")
+	}
+
+	userID, err := strconv.Atoi(c.QueryParam("user_id"))
+	if err != nil {
+		return badRequest(c, "invalid user_id")
+	}
+
+	if err := h.leadService.Remove(principalFrom(c), taskName, userID); err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"status": "success",
+	})
+}
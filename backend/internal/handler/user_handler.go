@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type UserHandler struct {
+	userService *service.UserService
+}
+
+func NewUserHandler(userService *service.UserService) *UserHandler {
+	return &UserHandler{
+		userService: userService,
+	}
+}
+
+// GetUsers ユーザーの一覧（?group=1年&include_inactive=true）
+func (h *UserHandler) GetUsers(c echo.Context) error {
+	users, err := h.userService.List(model.UserFilter{
+		Group:           c.QueryParam("group"),
+		IncludeInactive: c.QueryParam("include_inactive") == "true",
+	})
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"users": users,
+	})
+}
+
+// CreateUser ユーザーを追加（{"name": "田中", "slack_user_id": "U012ABC", "group": "1年"}）
+func (h *UserHandler) CreateUser(c echo.Context) error {
+	var req model.CreateUserRequest
+	if err := c.Bind(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+
+	user, err := h.userService.Create(req)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, user)
+}
+
+// UpdateUser ユーザーの名前・SlackのユーザーID・メールアドレス・言語・所属グループを変更（送った項目のみ）
+func (h *UserHandler) UpdateUser(c echo.Context) error {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return badRequest(c, "invalid user id")
+	}
+
+	var req model.UpdateUserRequest
+	if err := c.Bind(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+
+	user, changes, err := h.userService.Update(userID, req)
+	if err != nil {
+		return respondError(c, err)
+	}
+	setAuditChanges(c, changes)
+
+	return c.JSON(http.StatusOK, user)
+}
+
+// DeactivateUser ユーザーを利用停止にする（シフトと履歴は残る）
+func (h *UserHandler) DeactivateUser(c echo.Context) error {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return badRequest(c, "invalid user id")
+	}
+
+	user, err := h.userService.Deactivate(principalFrom(c), userID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+// UpdateRole ユーザーの権限を変更（{"role": "lead"}）
+func (h *UserHandler) UpdateRole(c echo.Context) error {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return badRequest(c, "invalid user id")
+	}
+
+	var req model.UpdateRoleRequest
+	if err := c.Bind(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+
+	user, err := h.userService.UpdateRole(principalFrom(c), userID, req.Role)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
@@ -1,27 +1,105 @@
 package model
 
 import (
-    "encoding/json"
-    "time"
+	"encoding/json"
+	"time"
 )
 
 type ActionLog struct {
-    ID          int             `json:"id" db:"id"`
-    ShiftID     int             `json:"shift_id" db:"shift_id"`
-    ActionType  string          `json:"action_type" db:"action_type"`
-    DiffPayload json.RawMessage `json:"diff_payload" db:"diff_payload"` // JSONB対応
-    CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	ID          int             `json:"id" db:"id"`
+	ShiftID     int             `json:"shift_id" db:"shift_id"`
+	SyncID      *int            `json:"sync_id" db:"sync_id"` // 同期より前の履歴はnull
+	ActionType  string          `json:"action_type" db:"action_type"`
+	DiffPayload json.RawMessage `json:"diff_payload" db:"diff_payload"` // JSONB対応
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
 }
 
 type DiffPayload struct {
-	User string `json:"user"`
-	Date string `json:"date"`
-	Slot string `json:"slot"`
+	User    string       `json:"user"`
+	Date    string       `json:"date"`
+	Slot    string       `json:"slot"`
 	Changes []ChangeItem `json:"changes"`
 }
 
 type ChangeItem struct {
 	Field string `json:"field"`
-	Old string `json:"old"`
-	New string `json:"new"`
-}
\ No newline at end of file
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// ActionLogEntry action_logに対象シフトの情報を付けたもの
+type ActionLogEntry struct {
+	ActionLog
+	YearID   int    `json:"year_id" db:"year_id"`
+	TimeID   int    `json:"time_id" db:"time_id"`
+	Date     string `json:"date" db:"date"`
+	Weather  string `json:"weather" db:"weather"`
+	UserID   int    `json:"user_id" db:"user_id"`
+	UserName string `json:"user_name" db:"user_name"`
+}
+
+// ShiftDiff diff_payload をアクション種別によらず同じ形にしたもの
+// CREATE / DELETE も task_name の変更（空文字からの追加・空文字への削除）として Changes に入る
+// 天気が変わった UPDATE は weather の変更も Changes に入る
+type ShiftDiff struct {
+	OldTaskName string       `json:"old_task_name"`
+	NewTaskName string       `json:"new_task_name"`
+	OldWeather  string       `json:"old_weather,omitempty"` // 天気が変わった場合のみ
+	NewWeather  string       `json:"new_weather,omitempty"`
+	Changes     []ChangeItem `json:"changes"`
+}
+
+// Diff diff_payload を ShiftDiff に変換する
+func (l *ActionLog) Diff() (ShiftDiff, error) {
+	var raw struct {
+		NewTask     string       `json:"new_task"`
+		DeletedTask string       `json:"deleted_task"`
+		Changes     []ChangeItem `json:"changes"`
+	}
+	if err := json.Unmarshal(l.DiffPayload, &raw); err != nil {
+		return ShiftDiff{Changes: []ChangeItem{}}, err
+	}
+
+	var diff ShiftDiff
+	switch l.ActionType {
+	case "CREATE":
+		diff.NewTaskName = raw.NewTask
+		diff.Changes = []ChangeItem{{Field: "task_name", Old: "", New: raw.NewTask}}
+	case "DELETE":
+		diff.OldTaskName = raw.DeletedTask
+		diff.Changes = []ChangeItem{{Field: "task_name", Old: raw.DeletedTask, New: ""}}
+	default:
+		diff.Changes = raw.Changes
+		for _, c := range raw.Changes {
+			switch c.Field {
+			case "task_name":
+				diff.OldTaskName, diff.NewTaskName = c.Old, c.New
+			case "weather":
+				diff.OldWeather, diff.NewWeather = c.Old, c.New
+			}
+		}
+	}
+	if diff.Changes == nil {
+		diff.Changes = []ChangeItem{}
+	}
+	return diff, nil
+}
+
+// TaskChange diff_payloadから変更前後のタスク名を取り出す
+// CREATEなら oldTask が、DELETEなら newTask が空になる
+func (l *ActionLog) TaskChange() (oldTask, newTask string) {
+	diff, err := l.Diff()
+	if err != nil {
+		return "", ""
+	}
+	return diff.OldTaskName, diff.NewTaskName
+}
+
+// WeatherChange diff_payloadから変更前後の天気を取り出す（天気が変わっていなければ changed が false）
+func (l *ActionLog) WeatherChange() (oldWeather, newWeather string, changed bool) {
+	diff, err := l.Diff()
+	if err != nil || diff.OldWeather == diff.NewWeather {
+		return "", "", false
+	}
+	return diff.OldWeather, diff.NewWeather, true
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLog 管理操作（権限が必要な操作）の記録
+type AuditLog struct {
+	ID          int             `json:"id" db:"id"`
+	ActorUserID *int            `json:"actor_user_id" db:"actor_user_id"` // 操作したユーザー（削除されたらnull）
+	ActorName   string          `json:"actor_name" db:"actor_name"`
+	ActorRole   string          `json:"actor_role" db:"actor_role"`
+	Action      string          `json:"action" db:"action"` // "POST /api/task_leads" など
+	Target      string          `json:"target" db:"target"` // 実際のパス（"/api/users/3/role" など）
+	Status      int             `json:"status" db:"status"` // レスポンスのステータスコード
+	Detail      json.RawMessage `json:"detail" db:"detail"` // クエリ・リクエストボディなど
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+}
+
+// AuditLogFilter 管理操作の記録の検索条件（0は条件なし）
+type AuditLogFilter struct {
+	ActorUserID int
+	Limit       int
+}
+
+// UpdateRoleRequest 権限変更APIのリクエストボディ
+type UpdateRoleRequest struct {
+	Role string `json:"role"`
+}
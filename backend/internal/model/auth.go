@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// Principal 認証済みのリクエストの利用者
+type Principal struct {
+	UserID      int      `json:"user_id"`
+	Name        string   `json:"name"`
+	SlackUserID string   `json:"slack_user_id"`
+	Role        string   `json:"role"`
+	LeadTasks   []string `json:"lead_tasks"` // リーダーを務めるタスク（role が lead のときのみ）
+}
+
+// HasRole いずれかの権限を持っているか
+func (p *Principal) HasRole(roles ...string) bool {
+	for _, r := range roles {
+		if p.Role == r {
+			return true
+		}
+	}
+	return false
+}
+
+// CanManageTask タスクを閲覧・操作できるか（admin は全て、lead は担当タスクのみ）
+func (p *Principal) CanManageTask(taskName string) bool {
+	if p.Role == RoleAdmin {
+		return true
+	}
+	if p.Role != RoleLead {
+		return false
+	}
+	for _, t := range p.LeadTasks {
+		if t == taskName {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthToken ログインで発行するセッショントークン
+type AuthToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	User      *User     `json:"user"`
+}
+
+// SlackIdentity Sign in with Slack (OIDC) の ID トークンから取り出した利用者
+type SlackIdentity struct {
+	SlackUserID string
+	TeamID      string
+	Name        string
+	Email       string
+}
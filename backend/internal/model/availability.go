@@ -0,0 +1,78 @@
+package model
+
+import "time"
+
+// NGTaskName シートで参加できない枠に入れるタスク名
+const NGTaskName = "NG"
+
+// NGRange ボランティアが申告した参加できない時間帯
+// StartTimeID から EndTimeID の手前までの枠がNG
+type NGRange struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"user_id"`
+	UserName    string    `json:"user_name"`
+	YearID      int       `json:"year_id"`
+	Date        string    `json:"date"`
+	Weather     string    `json:"weather"`
+	StartTimeID int       `json:"start_time_id"`
+	EndTimeID   int       `json:"end_time_id"`
+	Start       string    `json:"start"` // "10:00"
+	End         string    `json:"end"`   // "12:00"
+	Note        string    `json:"note"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Covers その枠がこの時間帯に含まれるか
+func (r *NGRange) Covers(yearID, timeID int, date, weather string) bool {
+	return r.YearID == yearID && r.Date == date && r.Weather == weather &&
+		r.StartTimeID <= timeID && timeID < r.EndTimeID
+}
+
+// AvailabilityRequest 参加できない時間帯の申告（年度ごとに、送った内容で置き換える）
+type AvailabilityRequest struct {
+	YearID int            `json:"year_id"`
+	Ranges []NGRangeInput `json:"ranges"`
+}
+
+// NGRangeInput 申告する時間帯1件分（from 〜 to の手前までがNG）
+type NGRangeInput struct {
+	Date    string `json:"date"`
+	Weather string `json:"weather"`
+	From    string `json:"from"` // "10:00"
+	To      string `json:"to"`   // "12:00"
+	Note    string `json:"note"`
+}
+
+// Availability ユーザーのある年度のNGの申告
+type Availability struct {
+	UserID int        `json:"user_id"`
+	YearID int        `json:"year_id"`
+	Ranges []*NGRange `json:"ranges"`
+}
+
+// AvailabilityFilter NGの一覧の絞り込み（0・空文字なら絞り込まない）
+type AvailabilityFilter struct {
+	YearID  int
+	UserID  int
+	Date    string
+	Weather string
+}
+
+// NGConflict 同期で、NGを申告した枠にタスクが割り当てられていたもの
+type NGConflict struct {
+	YearID   int    `json:"yearID"`
+	TimeID   int    `json:"timeID"`
+	Date     string `json:"date"`
+	Weather  string `json:"weather"`
+	UserName string `json:"userName"`
+	TaskName string `json:"taskName"`
+	Note     string `json:"note"`
+}
+
+// SyncResult GASからの同期の結果
+type SyncResult struct {
+	SyncID      int           `json:"sync_id"`
+	NGConflicts []*NGConflict `json:"ng_conflicts"`
+	// シートへの書き戻し待ちで、この同期では触らなかった交換
+	PendingSwapIDs []int `json:"pending_swap_ids"`
+}
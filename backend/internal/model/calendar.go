@@ -0,0 +1,8 @@
+package model
+
+// CalendarToken カレンダー購読用URLの発行結果（トークンは発行時にしか返さない）
+type CalendarToken struct {
+	UserID int    `json:"user_id"`
+	Token  string `json:"token"`
+	URL    string `json:"url"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// ChangeDigest 指定期間の全変更をまとめたもの（運営チャンネル向け）
+type ChangeDigest struct {
+	From      time.Time              `json:"from"`
+	To        time.Time              `json:"to"`
+	Total     int                    `json:"total"`
+	Groups    []ChangeDigestGroup    `json:"groups"`
+	Deletions []ChangeDigestDeletion `json:"deletions"`
+}
+
+// ChangeDigestGroup 日付ラベル・タスク・アクション種別ごとの件数
+type ChangeDigestGroup struct {
+	Date       string `json:"date"`
+	TaskName   string `json:"task_name"`
+	ActionType string `json:"action_type"`
+	Count      int    `json:"count"`
+}
+
+// ChangeDigestDeletion 特に目立たせたい削除（実際のタスクから外れたもの）
+type ChangeDigestDeletion struct {
+	UserName  string    `json:"user_name"`
+	Date      string    `json:"date"`
+	TimeID    int       `json:"time_id"`
+	Time      string    `json:"time"`
+	Weather   string    `json:"weather"`
+	TaskName  string    `json:"task_name"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
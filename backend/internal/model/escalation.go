@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// 未読のままの変更への催促の段階
+const (
+	EscalationStageReminder = "reminder" // 本人への再送
+	EscalationStageLead     = "lead"     // タスクリーダーへの通知
+)
+
+// UnacknowledgedChange 開催直前に変わったのに、本人がまだ既読にしていないシフトの変更
+// シフトごとに最新の変更だけを対象にする
+type UnacknowledgedChange struct {
+	ActionLogID    int        `json:"action_log_id"`
+	ShiftID        int        `json:"shift_id"`
+	ActionType     string     `json:"action_type"`
+	OldTaskName    string     `json:"old_task_name"`
+	NewTaskName    string     `json:"new_task_name"`
+	TaskName       string     `json:"task_name"` // 変更後のタスク（削除なら変更前のタスク）
+	UserID         int        `json:"user_id"`
+	UserName       string     `json:"user_name"`
+	YearID         int        `json:"year_id"`
+	Date           string     `json:"date"`
+	Weather        string     `json:"weather"`
+	TimeID         int        `json:"time_id"`
+	StartsAt       time.Time  `json:"starts_at"`
+	ChangedAt      time.Time  `json:"changed_at"`
+	ReminderSentAt *time.Time `json:"reminder_sent_at"`
+	LeadNotifiedAt *time.Time `json:"lead_notified_at"`
+}
+
+// UnacknowledgedFilter 未読のままの変更の一覧の絞り込み（0・空文字なら絞り込まない）
+type UnacknowledgedFilter struct {
+	UserID   int
+	TaskName string
+}
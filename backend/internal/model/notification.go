@@ -1,25 +1,19 @@
 package model
 
-// Notification 通知データ
-type Notification struct {
-	ID              int    `json:"id"`
-	UserID          int    `json:"user_id"`
-	ShiftID         int    `json:"shift_id"`
-	YearID          int    `json:"year_id"`
-	TimeID          int    `json:"time_id"`
-	Date            string `json:"date"`
-	Weather         string `json:"weather"`
-	OldTaskName     string `json:"old_task_name"`
-	NewTaskName     string `json:"new_task_name"`
-	IsRead          bool   `json:"is_read"`
-	SlackDMSent     bool   `json:"slack_dm_sent"`
-	SlackChannelSent bool   `json:"slack_channel_sent"`
-	CreatedAt       string `json:"created_at"`
+import "time"
+
+// NotificationEntry 通知一覧用の変更履歴（既読状態付き）
+// 通知テーブルは廃止したので、action_log と shift_reads から組み立てる
+type NotificationEntry struct {
+	ActionLogEntry
+	IsRead bool `json:"is_read"`
 }
 
-// NotificationResponse APIレスポンス用
+// NotificationResponse APIレスポンス用（Flutterの ShiftNotification と同じ形）
 type NotificationResponse struct {
-	ID          int    `json:"id"`
+	ID          int    `json:"id"` // action_log のID
+	ShiftID     int    `json:"shift_id"`
+	ActionType  string `json:"action_type"` // "CREATE", "UPDATE", "DELETE"
 	UserName    string `json:"user_name"`
 	YearID      int    `json:"year_id"`
 	TimeID      int    `json:"time_id"`
@@ -31,3 +25,27 @@ type NotificationResponse struct {
 	CreatedAt   string `json:"created_at"`
 }
 
+// TimelineCursor タイムラインのページ位置（前のページの最後の1件）
+type TimelineCursor struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+// TimelineFilter タイムライン・通知一覧の検索条件（空の項目は条件なし）
+type TimelineFilter struct {
+	UserID      int
+	UnreadOnly  bool
+	Date        string   // 日付ラベル ("1日目" など)
+	Weather     string   // "晴れ" / "雨"
+	ActionTypes []string // "CREATE", "UPDATE", "DELETE"
+	Before      *TimelineCursor
+	AfterID     int  // このIDより後の変更のみ（SSEの再開用）
+	Ascending   bool // 古い順にする
+	Limit       int
+}
+
+// TimelinePage タイムラインの1ページ分
+type TimelinePage struct {
+	Entries    []NotificationResponse `json:"entries"`
+	NextCursor *string                `json:"next_cursor"` // 次のページが無ければnull
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// 配信の状態
+const (
+	DeliveryStatusQueued     = "queued"     // 送信待ち（digestの場合は次回のまとめ送信待ち）
+	DeliveryStatusSent       = "sent"       // 送信済み
+	DeliveryStatusFailed     = "failed"     // 送信失敗
+	DeliveryStatusSuppressed = "suppressed" // 通知設定などにより送らなかった
+)
+
+// 配信経路（NotificationPreference の dm / email に加えてチャンネル投稿がある）
+const (
+	DeliveryRouteDM      = "dm"
+	DeliveryRouteEmail   = "email"
+	DeliveryRouteChannel = "channel"
+)
+
+// NotificationDelivery 通知1件ごとの配信記録
+type NotificationDelivery struct {
+	ID          int       `json:"id" db:"id"`
+	ActionLogID *int      `json:"action_log_id" db:"action_log_id"` // シフト変更以外（リーダー向けまとめ等）はnull
+	SyncID      *int      `json:"sync_id" db:"sync_id"`
+	ShiftID     *int      `json:"shift_id" db:"shift_id"`
+	UserID      *int      `json:"user_id" db:"user_id"` // 受信者（チャンネル投稿はnull）
+	Kind        string    `json:"kind" db:"kind"`       // "CREATE", "UPDATE", "DELETE", "LEAD_SUMMARY", "STAFFING_ALERT"
+	Channel     string    `json:"channel" db:"channel"` // "dm", "email", "channel"
+	Recipient   string    `json:"recipient" db:"recipient"`
+	Status      string    `json:"status" db:"status"`
+	SlackTS     string    `json:"slack_ts" db:"slack_ts"`
+	Attempts    int       `json:"attempts" db:"attempts"`
+	LastError   string    `json:"last_error" db:"last_error"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DeliveryFilter 配信記録の検索条件（0は条件なし）
+type DeliveryFilter struct {
+	UserID  int
+	ShiftID int
+	SyncID  int
+	Status  string
+	Limit   int
+}
@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+// 通知の送り先
+const (
+	DeliveryChannelDM    = "dm"
+	DeliveryChannelEmail = "email"
+	DeliveryChannelNone  = "none"
+)
+
+// 通知のタイミング
+const (
+	DeliveryModeRealtime = "realtime" // 変更のたびにすぐ送る
+	DeliveryModeDigest   = "digest"   // 一定間隔でまとめて送る
+)
+
+// NotificationPreference ユーザーごとの通知設定
+type NotificationPreference struct {
+	UserID          int    `json:"user_id" db:"user_id"`
+	NotifyCreate    bool   `json:"notify_create" db:"notify_create"`
+	NotifyUpdate    bool   `json:"notify_update" db:"notify_update"`
+	NotifyDelete    bool   `json:"notify_delete" db:"notify_delete"`
+	DeliveryChannel string `json:"delivery_channel" db:"delivery_channel"` // "dm", "email", "none"
+	DeliveryMode    string `json:"delivery_mode" db:"delivery_mode"`       // "realtime", "digest"
+	// 実施されない方の天気プラン（例: 晴れの日の「雨」シフト）の変更も受け取るか
+	IncludeInactiveWeather bool `json:"include_inactive_weather" db:"include_inactive_weather"`
+	// シフト開始までこの時間以内の変更だけを受け取る（0なら制限なし）
+	WithinHours  int        `json:"within_hours" db:"within_hours"`
+	LastDigestAt *time.Time `json:"last_digest_at" db:"last_digest_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// DefaultNotificationPreference 設定を保存していないユーザー用の既定値（今まで通り全件DM）
+func DefaultNotificationPreference(userID int) *NotificationPreference {
+	return &NotificationPreference{
+		UserID:                 userID,
+		NotifyCreate:           true,
+		NotifyUpdate:           true,
+		NotifyDelete:           true,
+		DeliveryChannel:        DeliveryChannelDM,
+		DeliveryMode:           DeliveryModeRealtime,
+		IncludeInactiveWeather: true,
+		WithinHours:            0,
+	}
+}
+
+// AllowsAction 指定したアクション種別を受け取る設定か
+func (p *NotificationPreference) AllowsAction(actionType string) bool {
+	switch actionType {
+	case "CREATE":
+		return p.NotifyCreate
+	case "UPDATE":
+		return p.NotifyUpdate
+	case "DELETE":
+		return p.NotifyDelete
+	default:
+		return true
+	}
+}
+
+// NotificationPreferenceRequest 通知設定更新APIのリクエストボディ
+type NotificationPreferenceRequest struct {
+	NotifyCreate           bool   `json:"notify_create"`
+	NotifyUpdate           bool   `json:"notify_update"`
+	NotifyDelete           bool   `json:"notify_delete"`
+	DeliveryChannel        string `json:"delivery_channel"`
+	DeliveryMode           string `json:"delivery_mode"`
+	IncludeInactiveWeather bool   `json:"include_inactive_weather"`
+	WithinHours            int    `json:"within_hours"`
+}
@@ -0,0 +1,164 @@
+package model
+
+// ScheduleGridFilter スケジュール表の取得条件（Task / Group は空なら条件なし）
+type ScheduleGridFilter struct {
+	YearID  int
+	Date    string
+	Weather string
+	Task    string
+	Group   string
+}
+
+// GridShift スケジュール表の1セル分のシフト
+type GridShift struct {
+	UserID    int
+	UserName  string
+	UserGroup string
+	TimeID    int
+	TaskName  string
+}
+
+// ScheduleGrid ユーザー × 時間枠のスケジュール表
+// セルはタスク名の代わりに Tasks のインデックスを持つ（空きは null）
+type ScheduleGrid struct {
+	YearID  int           `json:"year_id"`
+	Date    string        `json:"date"`
+	Weather string        `json:"weather"`
+	Slots   []GridSlot    `json:"slots"`
+	Tasks   []string      `json:"tasks"`
+	Rows    []GridUserRow `json:"rows"`
+}
+
+// GridSlot スケジュール表の列（時間枠）
+type GridSlot struct {
+	TimeID int    `json:"time_id"`
+	Start  string `json:"start"` // "06:00"
+	End    string `json:"end"`   // "06:30"
+}
+
+// GridUserRow スケジュール表の行（ユーザー）。Cells は Slots と同じ並び
+type GridUserRow struct {
+	UserID   int    `json:"user_id"`
+	UserName string `json:"user_name"`
+	Group    string `json:"group"`
+	Cells    []*int `json:"cells"`
+}
+
+// シフト検索の並び替えキー
+const (
+	ShiftSortDate    = "date"
+	ShiftSortTime    = "time"
+	ShiftSortUser    = "user"
+	ShiftSortTask    = "task"
+	ShiftSortWeather = "weather"
+	ShiftSortYear    = "year"
+)
+
+// ValidShiftSortField 並び替えキーとして使えるか
+func ValidShiftSortField(field string) bool {
+	switch field {
+	case ShiftSortDate, ShiftSortTime, ShiftSortUser, ShiftSortTask, ShiftSortWeather, ShiftSortYear:
+		return true
+	}
+	return false
+}
+
+// タスク名の一致方法
+const (
+	TaskMatchContains = "contains"
+	TaskMatchPrefix   = "prefix"
+)
+
+// ShiftSort 並び替え条件1つ分
+type ShiftSort struct {
+	Field string
+	Desc  bool
+}
+
+// ShiftSearchFilter シフト検索の条件（0・空は条件なし）
+// FromTimeID / ToTimeID は [From, To) の範囲に始まる枠を対象にする
+type ShiftSearchFilter struct {
+	YearID     int
+	UserName   string // 部分一致
+	Task       string
+	TaskMatch  string // TaskMatchContains / TaskMatchPrefix
+	Date       string
+	Weather    string
+	FromTimeID int
+	ToTimeID   int
+	Sort       []ShiftSort
+	Limit      int
+	Offset     int
+}
+
+// ShiftSearchResult 検索結果のシフト（担当者名と時刻付き）
+type ShiftSearchResult struct {
+	*Shift
+	UserName  string `json:"user_name"`
+	UserGroup string `json:"user_group"`
+	Start     string `json:"start"` // "06:00"
+	End       string `json:"end"`   // "06:30"
+}
+
+// ShiftSearchPage シフト検索の1ページ分
+type ShiftSearchPage struct {
+	Shifts []*ShiftSearchResult `json:"shifts"`
+	Total  int                  `json:"total"` // 条件に一致する全件数
+	Limit  int                  `json:"limit"`
+	Offset int                  `json:"offset"`
+}
+
+// TaskSummary タスクごとの割り当ての集計
+type TaskSummary struct {
+	TaskName   string  `json:"task_name"`
+	SlotCount  int     `json:"slot_count"`  // 割り当てられた枠の延べ数
+	UserCount  int     `json:"user_count"`  // 担当する人数
+	TotalHours float64 `json:"total_hours"` // 延べ時間
+}
+
+// TaskShift タスク別の表示用のシフト1枠分
+type TaskShift struct {
+	YearID   int
+	Date     string
+	Weather  string
+	TimeID   int
+	UserID   int
+	UserName string
+}
+
+// TaskRoster タスクの担当表（日付・天気ごと）
+type TaskRoster struct {
+	TaskName string          `json:"task_name"`
+	Days     []TaskRosterDay `json:"days"`
+}
+
+// TaskRosterDay 1つの日付・天気での担当表
+type TaskRosterDay struct {
+	YearID  int           `json:"year_id"`
+	Date    string        `json:"date"`
+	Weather string        `json:"weather"`
+	Blocks  []RosterBlock `json:"blocks"`
+}
+
+// RosterBlock 誰かが割り当てられている連続した時間帯
+type RosterBlock struct {
+	Start     string         `json:"start"` // "09:00"
+	End       string         `json:"end"`   // "11:00"
+	Assignees []RosterMember `json:"assignees"`
+	Slots     []RosterSlot   `json:"slots"`
+}
+
+// RosterSlot 時間枠ごとの人数と担当者
+type RosterSlot struct {
+	TimeID    int            `json:"time_id"`
+	Start     string         `json:"start"`
+	End       string         `json:"end"`
+	Headcount int            `json:"headcount"`
+	Members   []RosterMember `json:"members"`
+}
+
+// RosterMember 担当者
+type RosterMember struct {
+	UserID   int    `json:"user_id"`
+	UserName string `json:"user_name"`
+}
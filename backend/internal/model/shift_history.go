@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// ShiftHistory 1シフトの変更履歴（削除済みのシフトも含む）
+type ShiftHistory struct {
+	Shift   *Shift              `json:"shift"`
+	Entries []ShiftHistoryEntry `json:"entries"`
+}
+
+// ShiftHistoryEntry 変更履歴1件
+type ShiftHistoryEntry struct {
+	ID         int       `json:"id"` // action_log のID
+	SyncID     *int      `json:"sync_id"`
+	ActionType string    `json:"action_type"` // "CREATE", "UPDATE", "DELETE"
+	Diff       ShiftDiff `json:"diff"`
+	CreatedAt  time.Time `json:"created_at"`
+}
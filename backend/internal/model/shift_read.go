@@ -3,9 +3,17 @@ package model
 import "time"
 
 type ShiftRead struct {
-    ID        int       `json:"id" db:"id"`
-    UserID    int       `json:"user_id" db:"user_id"`
-    ShiftID   int       `json:"shift_id" db:"shift_id"`
-    IsRead    bool      `json:"is_read" db:"is_read"`
-    UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
-}
\ No newline at end of file
+	ID        int        `json:"id" db:"id"`
+	UserID    int        `json:"user_id" db:"user_id"`
+	ShiftID   int        `json:"shift_id" db:"shift_id"`
+	IsRead    bool       `json:"is_read" db:"is_read"`
+	ReadAt    *time.Time `json:"read_at" db:"read_at"` // 最後に既読にした日時
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// MarkReadRequest まとめて既読にするリクエスト
+// shift_ids を指定するか、before 以前の変更しかないシフトを全て既読にする
+type MarkReadRequest struct {
+	ShiftIDs []int      `json:"shift_ids"`
+	Before   *time.Time `json:"before"`
+}
@@ -0,0 +1,81 @@
+package model
+
+import "time"
+
+// シフト交換の状態
+// pending -> (相手が承諾) accepted -> (リーダーが承認) approved
+// タスクの違うシフトどうしの交換は、両方のタスクのリーダーが承認すると approved になる（admin は一度で承認できる）
+// pending は相手が断ると declined、accepted はリーダーが却下すると rejected になる
+// 申請者は pending / accepted のうちは取り下げられる (cancelled)
+const (
+	SwapStatusPending   = "pending"
+	SwapStatusAccepted  = "accepted"
+	SwapStatusDeclined  = "declined"
+	SwapStatusApproved  = "approved"
+	SwapStatusRejected  = "rejected"
+	SwapStatusCancelled = "cancelled"
+)
+
+// ValidSwapStatus シフト交換の状態として使える値か
+func ValidSwapStatus(status string) bool {
+	switch status {
+	case SwapStatusPending, SwapStatusAccepted, SwapStatusDeclined,
+		SwapStatusApproved, SwapStatusRejected, SwapStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// ShiftSwap シフト交換の申請
+type ShiftSwap struct {
+	ID            int        `json:"id"`
+	Status        string     `json:"status"`
+	Message       string     `json:"message"`
+	Requester     SwapSide   `json:"requester"`
+	Counterpart   SwapSide   `json:"counterpart"`
+	ApproverID    *int       `json:"approver_id"`    // 承認・却下したリーダー（承認が複数なら最後に承認したリーダー）
+	ApprovedTasks []string   `json:"approved_tasks"` // 承認を済ませたタスク
+	SyncID        *int       `json:"sync_id"`        // 反映したときの変更履歴のまとまり
+	ExportedAt    *time.Time `json:"exported_at"`    // GASがシートに書き戻した日時
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// SwapSide 交換の片側（誰のどの枠か）
+// TaskName は申請時点のタスク名
+type SwapSide struct {
+	UserID   int    `json:"user_id"`
+	UserName string `json:"user_name"`
+	ShiftID  int    `json:"shift_id"`
+	YearID   int    `json:"year_id"`
+	TimeID   int    `json:"time_id"`
+	Date     string `json:"date"`
+	Weather  string `json:"weather"`
+	TaskName string `json:"task_name"`
+}
+
+// ShiftSwapRequest シフト交換の申請APIのリクエストボディ
+// 自分のシフト shift_id と、相手のシフト counterpart_shift_id を入れ替える
+type ShiftSwapRequest struct {
+	ShiftID            int    `json:"shift_id"`
+	CounterpartShiftID int    `json:"counterpart_shift_id"`
+	Message            string `json:"message"`
+}
+
+// ShiftSwapFilter シフト交換一覧の絞り込み
+type ShiftSwapFilter struct {
+	UserID int    // 申請者・相手のどちらかがこのユーザー（0なら全て）
+	Status string // 空なら全て
+}
+
+// ShiftSwapExport 承認済みの交換と、シートに書き戻すセルの変更（GAS向け）
+// taskName が空のセルは空欄にする
+type ShiftSwapExport struct {
+	SwapID  int           `json:"swap_id"`
+	Changes []ShiftChange `json:"changes"`
+}
+
+// ShiftSwapAckRequest シートへの書き戻しが済んだ交換のID（GAS向け）
+type ShiftSwapAckRequest struct {
+	SwapIDs []int `json:"swap_ids"`
+}
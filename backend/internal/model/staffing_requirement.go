@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// StaffingRequirement タスク・枠ごとの必要人数
+type StaffingRequirement struct {
+	ID        int       `json:"id" db:"id"`
+	YearID    int       `json:"year_id" db:"year_id"`
+	TaskName  string    `json:"task_name" db:"task_name"`
+	Date      string    `json:"date" db:"date"`
+	Weather   string    `json:"weather" db:"weather"`
+	TimeID    int       `json:"time_id" db:"time_id"`
+	MinStaff  int       `json:"min_staff" db:"min_staff"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// StaffingRequirementRequest 必要人数の一括登録リクエスト（スプレッドシートから送る想定）
+type StaffingRequirementRequest struct {
+	Requirements []StaffingRequirementItem `json:"requirements"`
+}
+
+// StaffingRequirementItem 必要人数1件分
+type StaffingRequirementItem struct {
+	YearID   int    `json:"yearID"`
+	TaskName string `json:"taskName"`
+	Date     string `json:"date"`
+	Weather  string `json:"weather"`
+	TimeID   int    `json:"timeID"`
+	MinStaff int    `json:"minStaff"`
+}
+
+// StaffingCoverage 必要人数に対する現在の配置人数
+type StaffingCoverage struct {
+	YearID   int    `json:"year_id"`
+	TaskName string `json:"task_name"`
+	Date     string `json:"date"`
+	Weather  string `json:"weather"`
+	TimeID   int    `json:"time_id"`
+	Time     string `json:"time"`
+	MinStaff int    `json:"min_staff"`
+	Assigned int    `json:"assigned"`
+	Shortage int    `json:"shortage"` // 不足人数（足りていれば0）
+}
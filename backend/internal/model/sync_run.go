@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// SyncRun GASからの同期(update_shifts) 1回分
+type SyncRun struct {
+	ID          int       `json:"id" db:"id"`
+	ChangeCount int       `json:"change_count" db:"change_count"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
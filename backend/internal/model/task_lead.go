@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// TaskLead タスクとそのリーダー（ユーザー）の対応
+type TaskLead struct {
+	TaskName  string    `json:"task_name" db:"task_name"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	UserName  string    `json:"user_name" db:"user_name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// TaskLeadRequest リーダー登録APIのリクエストボディ
+type TaskLeadRequest struct {
+	TaskName string `json:"task_name"`
+	UserID   int    `json:"user_id"`
+}
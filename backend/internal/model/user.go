@@ -1,11 +1,84 @@
 package model
 
+import "time"
+
+// ユーザーの権限
+const (
+	RoleAdmin  = "admin"  // 全ての管理操作
+	RoleLead   = "lead"   // 担当タスク（task_leads）の閲覧・操作
+	RoleMember = "member" // 自分のシフトのみ
+)
+
+// ValidRole 権限として使える値か
+func ValidRole(role string) bool {
+	switch role {
+	case RoleAdmin, RoleLead, RoleMember:
+		return true
+	}
+	return false
+}
+
 // User ユーザーデータ
 type User struct {
-	ID          int    `json:"id"`
+	ID            int        `json:"id"`
+	Name          string     `json:"name"`
+	SlackUserID   string     `json:"slack_user_id"`
+	Email         string     `json:"email"`          // 未登録の場合は空文字
+	Role          string     `json:"role"`           // admin / lead / member
+	Group         string     `json:"group"`          // 所属グループ（未設定の場合は空文字）
+	Locale        string     `json:"locale"`         // 言語（ja / en）
+	DeactivatedAt *time.Time `json:"deactivated_at"` // 利用停止した日時（利用中はnull）
+	CreatedAt     string     `json:"created_at"`
+	UpdatedAt     string     `json:"updated_at"`
+}
+
+// Active 利用停止されていないか
+func (u *User) Active() bool {
+	return u.DeactivatedAt == nil
+}
+
+// ユーザーの言語
+const (
+	LocaleJa = "ja"
+	LocaleEn = "en"
+)
+
+// ValidLocale 言語として使える値か
+func ValidLocale(locale string) bool {
+	switch locale {
+	case LocaleJa, LocaleEn:
+		return true
+	}
+	return false
+}
+
+// CreateUserRequest ユーザー追加APIのリクエストボディ（locale を省略すると ja、role は member）
+type CreateUserRequest struct {
 	Name        string `json:"name"`
 	SlackUserID string `json:"slack_user_id"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
+	Email       string `json:"email"`
+	Locale      string `json:"locale"`
+	Group       string `json:"group"`
+}
+
+// UpdateUserRequest ユーザー更新APIのリクエストボディ（省略した項目は変更しない）
+type UpdateUserRequest struct {
+	Name        *string `json:"name"`
+	SlackUserID *string `json:"slack_user_id"`
+	Email       *string `json:"email"`
+	Locale      *string `json:"locale"`
+	Group       *string `json:"group"`
+}
+
+// UserFilter ユーザー一覧の検索条件
+type UserFilter struct {
+	Group           string
+	IncludeInactive bool // 利用停止したユーザーも含める
 }
 
+// UserFieldChange ユーザー更新で変わった項目（監査ログに残す）
+type UserFieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
@@ -3,6 +3,12 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
+
+	"seeft-slack-notification/internal/model"
+
+	"github.com/lib/pq"
 )
 
 type ActionLogRepository struct {
@@ -13,21 +19,241 @@ func NewActionLogRepository(db *sql.DB) *ActionLogRepository {
 	return &ActionLogRepository{db: db}
 }
 
-func (r *ActionLogRepository) Create(tx *sql.Tx, shiftID int, actionType string, diffPayload interface{}) error {
-	// payloadBytes, err := json.Marshal(diffPayload)
-	// if err != nil {
-	//     return err
-	// }
-
+// Create 変更履歴を保存し、IDを返す（通知の配信記録と紐付けるため）
+func (r *ActionLogRepository) Create(tx *sql.Tx, syncID, shiftID int, actionType string, diffPayload interface{}) (int, error) {
 	query := `
-		INSERT INTO action_log (shift_id, action_type, diff_payload)
-		VALUES ($1, $2, $3)`
+		INSERT INTO action_log (sync_id, shift_id, action_type, diff_payload)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`
 
 	// トランザクション(tx)を使用
-	_, err := tx.Exec(query, shiftID, actionType, diffPayload)
+	var id int
+	if err := tx.QueryRow(query, syncID, shiftID, actionType, diffPayload).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to create action log: %w", err)
+	}
+
+	return id, nil
+}
+
+// ※ created_at は TIMESTAMP(タイムゾーン無し, DBはUTC)なので、期間の指定はUTCに揃えて渡す
+
+// actionLogEntryColumns action_log に対象シフトとユーザーを JOIN したときの列
+// FROM action_log a JOIN shifts s ... JOIN users u ... と組み合わせて使う
+const actionLogEntryColumns = `a.id, a.shift_id, a.action_type, a.diff_payload, a.created_at,
+               s.year_id, s.time_id, s.date, s.weather, s.user_id, u.name`
+
+// queryActionLogEntries 変更履歴をクエリして ActionLogEntry の一覧に詰め替える
+func (r *ActionLogRepository) queryActionLogEntries(query string, args ...interface{}) ([]*model.ActionLogEntry, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return fmt.Errorf("failed to create action log: %w", err)
+		return nil, fmt.Errorf("failed to query action logs: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*model.ActionLogEntry, 0)
+	for rows.Next() {
+		var e model.ActionLogEntry
+		if err := rows.Scan(
+			&e.ID,
+			&e.ShiftID,
+			&e.ActionType,
+			&e.DiffPayload,
+			&e.CreatedAt,
+			&e.YearID,
+			&e.TimeID,
+			&e.Date,
+			&e.Weather,
+			&e.UserID,
+			&e.UserName,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan action log: %w", err)
+		}
+		entries = append(entries, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetByUserSince 指定ユーザーのシフトに関する、since以降の変更履歴を古い順に取得
+func (r *ActionLogRepository) GetByUserSince(userID int, since time.Time) ([]*model.ActionLogEntry, error) {
+	query := `
+        SELECT ` + actionLogEntryColumns + `
+        FROM action_log a
+        JOIN shifts s ON s.id = a.shift_id
+        JOIN users u ON u.id = s.user_id
+        WHERE s.user_id = $1 AND a.created_at > $2
+        ORDER BY a.created_at ASC, a.id ASC`
+
+	return r.queryActionLogEntries(query, userID, since.UTC())
+}
+
+// GetBetween [from, to) の期間に記録された全ての変更履歴を古い順に取得
+func (r *ActionLogRepository) GetBetween(from, to time.Time) ([]*model.ActionLogEntry, error) {
+	query := `
+        SELECT ` + actionLogEntryColumns + `
+        FROM action_log a
+        JOIN shifts s ON s.id = a.shift_id
+        JOIN users u ON u.id = s.user_id
+        WHERE a.created_at >= $1 AND a.created_at < $2
+        ORDER BY a.created_at ASC, a.id ASC`
+
+	return r.queryActionLogEntries(query, from.UTC(), to.UTC())
+}
+
+// GetTimeline 指定ユーザーのシフトの変更履歴を、既読状態付きで新しい順に取得
+// 既読は「シフトを既読にした後に起きた変更」のみ。既読にした後で再び変わった場合は未読に戻る
+func (r *ActionLogRepository) GetTimeline(filter model.TimelineFilter) ([]*model.NotificationEntry, error) {
+	args := []interface{}{filter.UserID}
+	conditions := []string{
+		"s.user_id = $1",
+		"a.action_type IN ('CREATE', 'UPDATE', 'DELETE')",
+	}
+
+	addCondition := func(format string, values ...interface{}) {
+		placeholders := make([]interface{}, len(values))
+		for i, v := range values {
+			args = append(args, v)
+			placeholders[i] = fmt.Sprintf("$%d", len(args))
+		}
+		conditions = append(conditions, fmt.Sprintf(format, placeholders...))
+	}
+	if filter.UnreadOnly {
+		conditions = append(conditions, "n.is_read = FALSE")
+	}
+	if filter.Date != "" {
+		addCondition("s.date = %s", filter.Date)
+	}
+	if filter.Weather != "" {
+		addCondition("s.weather = %s", filter.Weather)
+	}
+	if len(filter.ActionTypes) > 0 {
+		addCondition("a.action_type = ANY(%s)", pq.Array(filter.ActionTypes))
+	}
+	if filter.Before != nil {
+		// 前のページの最後の1件より古いもの（同時刻ならIDで比較）
+		addCondition("(a.created_at, a.id) < (%s, %s)", filter.Before.CreatedAt.UTC(), filter.Before.ID)
+	}
+
+	if filter.AfterID > 0 {
+		addCondition("a.id > %s", filter.AfterID)
+	}
+
+	order := "DESC"
+	if filter.Ascending {
+		order = "ASC"
+	}
+
+	query := `
+        SELECT ` + actionLogEntryColumns + `, n.is_read
+        FROM action_log a
+        JOIN shifts s ON s.id = a.shift_id
+        JOIN users u ON u.id = s.user_id
+        LEFT JOIN shift_reads sr ON sr.shift_id = a.shift_id AND sr.user_id = s.user_id
+        CROSS JOIN LATERAL (
+            SELECT COALESCE(sr.read_at >= a.created_at, FALSE) AS is_read
+        ) n
+        WHERE ` + strings.Join(conditions, " AND ") + `
+        ORDER BY a.created_at ` + order + `, a.id ` + order
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query timeline: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*model.NotificationEntry, 0)
+	for rows.Next() {
+		var e model.NotificationEntry
+		if err := rows.Scan(
+			&e.ID,
+			&e.ShiftID,
+			&e.ActionType,
+			&e.DiffPayload,
+			&e.CreatedAt,
+			&e.YearID,
+			&e.TimeID,
+			&e.Date,
+			&e.Weather,
+			&e.UserID,
+			&e.UserName,
+			&e.IsRead,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan timeline entry: %w", err)
+		}
+		entries = append(entries, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return entries, nil
+}
+
+// CountByUserShifts ユーザーのシフトごとの変更履歴の件数（シフトID -> 件数。履歴の無いシフトは含まない）
+func (r *ActionLogRepository) CountByUserShifts(userID int) (map[int]int, error) {
+	query := `
+        SELECT a.shift_id, COUNT(*)
+        FROM action_log a
+        JOIN shifts s ON s.id = a.shift_id
+        WHERE s.user_id = $1
+        GROUP BY a.shift_id`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count action logs: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int)
+	for rows.Next() {
+		var shiftID, count int
+		if err := rows.Scan(&shiftID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan action log count: %w", err)
+		}
+		counts[shiftID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetByShiftID 指定シフトの変更履歴を古い順に取得
+func (r *ActionLogRepository) GetByShiftID(shiftID int) ([]*model.ActionLog, error) {
+	query := `
+        SELECT id, shift_id, sync_id, action_type, diff_payload, created_at
+        FROM action_log
+        WHERE shift_id = $1
+        ORDER BY created_at ASC, id ASC`
+
+	rows, err := r.db.Query(query, shiftID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shift history: %w", err)
+	}
+	defer rows.Close()
+
+	logs := make([]*model.ActionLog, 0)
+	for rows.Next() {
+		var l model.ActionLog
+		if err := rows.Scan(&l.ID, &l.ShiftID, &l.SyncID, &l.ActionType, &l.DiffPayload, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan action log: %w", err)
+		}
+		logs = append(logs, &l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
 	}
 
-	return err
+	return logs, nil
 }
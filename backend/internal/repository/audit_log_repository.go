@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"seeft-slack-notification/internal/model"
+)
+
+type AuditLogRepository struct {
+	db *sql.DB
+}
+
+func NewAuditLogRepository(db *sql.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+// Create 管理操作を記録する（同じトランザクションで記録する場合はtxを渡す。nilならdbを使う）
+func (r *AuditLogRepository) Create(q DBTX, l *model.AuditLog) error {
+	if q == nil {
+		q = r.db
+	}
+	query := `
+        INSERT INTO audit_log (actor_user_id, actor_name, actor_role, action, target, status, detail)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id, created_at`
+
+	var detail interface{}
+	if len(l.Detail) > 0 {
+		detail = []byte(l.Detail)
+	}
+
+	err := q.QueryRow(query,
+		l.ActorUserID,
+		l.ActorName,
+		l.ActorRole,
+		l.Action,
+		l.Target,
+		l.Status,
+		detail,
+	).Scan(&l.ID, &l.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	return nil
+}
+
+// List 管理操作の記録を新しい順に取得
+func (r *AuditLogRepository) List(filter model.AuditLogFilter) ([]*model.AuditLog, error) {
+	query := `SELECT id, actor_user_id, actor_name, actor_role, action, target, status, detail, created_at
+	          FROM audit_log`
+
+	var args []interface{}
+	if filter.ActorUserID != 0 {
+		args = append(args, filter.ActorUserID)
+		query += " WHERE actor_user_id = $1"
+	}
+	query += " ORDER BY id DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	logs := make([]*model.AuditLog, 0)
+	for rows.Next() {
+		var l model.AuditLog
+		var detail []byte
+		if err := rows.Scan(
+			&l.ID,
+			&l.ActorUserID,
+			&l.ActorName,
+			&l.ActorRole,
+			&l.Action,
+			&l.Target,
+			&l.Status,
+			&detail,
+			&l.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		if len(detail) > 0 {
+			l.Detail = detail
+		}
+		logs = append(logs, &l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return logs, nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"seeft-slack-notification/internal/model"
+)
+
+type AvailabilityRepository struct {
+	db *sql.DB
+}
+
+func NewAvailabilityRepository(db *sql.DB) *AvailabilityRepository {
+	return &AvailabilityRepository{db: db}
+}
+
+// List 条件に一致するNGの時間帯を、日付・天気・ユーザー名・時間順に取得
+func (r *AvailabilityRepository) List(filter model.AvailabilityFilter) ([]*model.NGRange, error) {
+	query := `
+        SELECT a.id, a.user_id, u.name, a.year_id, a.date, a.weather, a.start_time_id, a.end_time_id, a.note, a.created_at
+        FROM availability_ng a
+        JOIN users u ON u.id = a.user_id
+        WHERE ($1 = 0 OR a.year_id = $1)
+          AND ($2 = 0 OR a.user_id = $2)
+          AND ($3 = '' OR a.date = $3)
+          AND ($4 = '' OR a.weather = $4)
+        ORDER BY a.year_id ASC, a.date ASC, a.weather ASC, u.name ASC, a.start_time_id ASC, a.id ASC`
+
+	rows, err := r.db.Query(query, filter.YearID, filter.UserID, filter.Date, filter.Weather)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query availability: %w", err)
+	}
+	defer rows.Close()
+
+	ranges := make([]*model.NGRange, 0)
+	for rows.Next() {
+		var ng model.NGRange
+		if err := rows.Scan(
+			&ng.ID,
+			&ng.UserID,
+			&ng.UserName,
+			&ng.YearID,
+			&ng.Date,
+			&ng.Weather,
+			&ng.StartTimeID,
+			&ng.EndTimeID,
+			&ng.Note,
+			&ng.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan availability: %w", err)
+		}
+		ranges = append(ranges, &ng)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return ranges, nil
+}
+
+// ReplaceForUser ユーザーのある年度のNGを全て入れ替える
+func (r *AvailabilityRepository) ReplaceForUser(tx *sql.Tx, userID, yearID int, ranges []*model.NGRange) error {
+	if _, err := tx.Exec(`DELETE FROM availability_ng WHERE user_id = $1 AND year_id = $2`, userID, yearID); err != nil {
+		return fmt.Errorf("failed to clear availability: %w", err)
+	}
+
+	query := `INSERT INTO availability_ng (user_id, year_id, date, weather, start_time_id, end_time_id, note)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7)
+	          RETURNING id, created_at`
+
+	for _, ng := range ranges {
+		if err := tx.QueryRow(query, userID, yearID, ng.Date, ng.Weather, ng.StartTimeID, ng.EndTimeID, ng.Note).Scan(&ng.ID, &ng.CreatedAt); err != nil {
+			return fmt.Errorf("failed to insert availability: %w", err)
+		}
+	}
+
+	return nil
+}
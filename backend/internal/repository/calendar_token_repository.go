@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type CalendarTokenRepository struct {
+	db *sql.DB
+}
+
+func NewCalendarTokenRepository(db *sql.DB) *CalendarTokenRepository {
+	return &CalendarTokenRepository{db: db}
+}
+
+// Upsert ユーザーのトークンのハッシュを保存する（既にあれば置き換える）
+func (r *CalendarTokenRepository) Upsert(userID int, tokenHash string) error {
+	query := `INSERT INTO calendar_tokens (user_id, token_hash)
+	          VALUES ($1, $2)
+	          ON CONFLICT (user_id) DO UPDATE
+	          SET token_hash = EXCLUDED.token_hash, created_at = CURRENT_TIMESTAMP`
+
+	if _, err := r.db.Exec(query, userID, tokenHash); err != nil {
+		return fmt.Errorf("failed to save calendar token: %w", err)
+	}
+	return nil
+}
+
+// GetHash ユーザーのトークンのハッシュ（未発行なら空文字）
+func (r *CalendarTokenRepository) GetHash(userID int) (string, error) {
+	var hash string
+	err := r.db.QueryRow(`SELECT token_hash FROM calendar_tokens WHERE user_id = $1`, userID).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get calendar token: %w", err)
+	}
+	return hash, nil
+}
@@ -0,0 +1,11 @@
+package repository
+
+import "database/sql"
+
+// DBTX *sql.DB と *sql.Tx のどちらでも受け取れるようにするためのインターフェース
+// 同期処理のトランザクション内からも、その外からも使うリポジトリメソッド用
+type DBTX interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrNotFound 対象のレコードが存在しない
+	ErrNotFound = errors.New("not found")
+	// ErrConflict 一意制約などにより、既存のレコードと矛盾する
+	ErrConflict = errors.New("conflict")
+)
+
+// PostgreSQL のエラーコード
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+// isPQError err が指定したコードの PostgreSQL のエラーか
+func isPQError(err error, code string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && string(pqErr.Code) == code
+}
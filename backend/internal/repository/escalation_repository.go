@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"seeft-slack-notification/internal/model"
+)
+
+type EscalationRepository struct {
+	db *sql.DB
+}
+
+func NewEscalationRepository(db *sql.DB) *EscalationRepository {
+	return &EscalationRepository{db: db}
+}
+
+// GetUnacknowledgedSince since 以降に変わったシフトのうち、最新の変更を本人がまだ既読にしていないもの
+// シフトごとに最新の変更1件と、その変更に送った催促の日時を返す（StartsAt は呼び出し側で埋める）
+func (r *EscalationRepository) GetUnacknowledgedSince(since time.Time) ([]*model.UnacknowledgedChange, error) {
+	query := `
+        SELECT c.id, c.shift_id, c.action_type, c.diff_payload, c.created_at,
+               c.task_name, c.user_id, c.name, c.year_id, c.date, c.weather, c.time_id,
+               er.created_at, el.created_at
+        FROM (
+            SELECT DISTINCT ON (a.shift_id)
+                   a.id, a.shift_id, a.action_type, a.diff_payload, a.created_at,
+                   s.task_name, s.user_id, u.name, s.year_id, s.date, s.weather, s.time_id
+            FROM action_log a
+            JOIN shifts s ON s.id = a.shift_id
+            JOIN users u ON u.id = s.user_id
+            WHERE a.action_type IN ('CREATE', 'UPDATE', 'DELETE')
+              AND a.created_at >= $1
+            ORDER BY a.shift_id, a.created_at DESC, a.id DESC
+        ) c
+        LEFT JOIN shift_reads sr ON sr.shift_id = c.shift_id AND sr.user_id = c.user_id
+        LEFT JOIN change_escalations er ON er.action_log_id = c.id AND er.stage = 'reminder'
+        LEFT JOIN change_escalations el ON el.action_log_id = c.id AND el.stage = 'lead'
+        WHERE NOT COALESCE(sr.read_at >= c.created_at, FALSE)`
+
+	rows, err := r.db.Query(query, since.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unacknowledged changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := make([]*model.UnacknowledgedChange, 0)
+	for rows.Next() {
+		var c model.UnacknowledgedChange
+		var payload json.RawMessage
+		if err := rows.Scan(
+			&c.ActionLogID,
+			&c.ShiftID,
+			&c.ActionType,
+			&payload,
+			&c.ChangedAt,
+			&c.TaskName,
+			&c.UserID,
+			&c.UserName,
+			&c.YearID,
+			&c.Date,
+			&c.Weather,
+			&c.TimeID,
+			&c.ReminderSentAt,
+			&c.LeadNotifiedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan unacknowledged change: %w", err)
+		}
+
+		entry := model.ActionLog{ActionType: c.ActionType, DiffPayload: payload}
+		c.OldTaskName, c.NewTaskName = entry.TaskChange()
+		changes = append(changes, &c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return changes, nil
+}
+
+// Record 変更に催促を送ったことを記録する（記録済みなら何もしない）
+func (r *EscalationRepository) Record(actionLogID int, stage string) error {
+	query := `INSERT INTO change_escalations (action_log_id, stage) VALUES ($1, $2)
+	          ON CONFLICT (action_log_id, stage) DO NOTHING`
+
+	if _, err := r.db.Exec(query, actionLogID, stage); err != nil {
+		return fmt.Errorf("failed to record escalation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"seeft-slack-notification/internal/model"
+
+	"github.com/lib/pq"
+)
+
+type NotificationDeliveryRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationDeliveryRepository(db *sql.DB) *NotificationDeliveryRepository {
+	return &NotificationDeliveryRepository{db: db}
+}
+
+// Create 配信記録を作成する（同期中はtxを渡す。nilならdbを使う）
+func (r *NotificationDeliveryRepository) Create(q DBTX, d *model.NotificationDelivery) error {
+	if q == nil {
+		q = r.db
+	}
+	query := `
+        INSERT INTO notification_deliveries
+            (action_log_id, sync_id, shift_id, user_id, kind, channel, recipient, status, last_error)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''))
+        RETURNING id, created_at, updated_at`
+
+	err := q.QueryRow(query,
+		d.ActionLogID,
+		d.SyncID,
+		d.ShiftID,
+		d.UserID,
+		d.Kind,
+		d.Channel,
+		d.Recipient,
+		d.Status,
+		d.LastError,
+	).Scan(&d.ID, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create notification delivery: %w", err)
+	}
+
+	return nil
+}
+
+// MarkSent 送信済みにする
+func (r *NotificationDeliveryRepository) MarkSent(id int, slackTS string, attempts int) error {
+	query := `UPDATE notification_deliveries
+	          SET status = $1, slack_ts = NULLIF($2, ''), attempts = attempts + $3, last_error = NULL,
+	              updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $4`
+
+	if _, err := r.db.Exec(query, model.DeliveryStatusSent, slackTS, attempts, id); err != nil {
+		return fmt.Errorf("failed to mark delivery sent: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed 送信失敗にする
+func (r *NotificationDeliveryRepository) MarkFailed(id int, attempts int, lastError string) error {
+	query := `UPDATE notification_deliveries
+	          SET status = $1, attempts = attempts + $2, last_error = $3, updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $4`
+
+	if _, err := r.db.Exec(query, model.DeliveryStatusFailed, attempts, lastError, id); err != nil {
+		return fmt.Errorf("failed to mark delivery failed: %w", err)
+	}
+	return nil
+}
+
+// MarkSuppressed 送信待ち(queued)の記録を suppressed にする
+func (r *NotificationDeliveryRepository) MarkSuppressed(ids []int) error {
+	query := `UPDATE notification_deliveries
+	          SET status = $1, updated_at = CURRENT_TIMESTAMP
+	          WHERE id = ANY($2) AND status = $3`
+
+	_, err := r.db.Exec(query, model.DeliveryStatusSuppressed, pq.Array(ids), model.DeliveryStatusQueued)
+	if err != nil {
+		return fmt.Errorf("failed to mark deliveries suppressed: %w", err)
+	}
+	return nil
+}
+
+// UpdateQueuedForActionLogs まとめ送信待ち(queued)の記録を、まとめ送信の結果で更新する
+func (r *NotificationDeliveryRepository) UpdateQueuedForActionLogs(userID int, actionLogIDs []int, status, slackTS string) error {
+	if len(actionLogIDs) == 0 {
+		return nil
+	}
+
+	query := `UPDATE notification_deliveries
+	          SET status = $1, slack_ts = NULLIF($2, ''), attempts = attempts + 1, updated_at = CURRENT_TIMESTAMP
+	          WHERE user_id = $3 AND action_log_id = ANY($4) AND status = $5`
+
+	_, err := r.db.Exec(query, status, slackTS, userID, pq.Array(actionLogIDs), model.DeliveryStatusQueued)
+	if err != nil {
+		return fmt.Errorf("failed to update digest deliveries: %w", err)
+	}
+	return nil
+}
+
+// List 条件に合う配信記録を新しい順に取得
+func (r *NotificationDeliveryRepository) List(filter model.DeliveryFilter) ([]*model.NotificationDelivery, error) {
+	var conditions []string
+	var args []interface{}
+
+	addCondition := func(column string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	if filter.UserID != 0 {
+		addCondition("user_id", filter.UserID)
+	}
+	if filter.ShiftID != 0 {
+		addCondition("shift_id", filter.ShiftID)
+	}
+	if filter.SyncID != 0 {
+		addCondition("sync_id", filter.SyncID)
+	}
+	if filter.Status != "" {
+		addCondition("status", filter.Status)
+	}
+
+	query := `SELECT id, action_log_id, sync_id, shift_id, user_id, kind, channel, recipient, status,
+	                 COALESCE(slack_ts, ''), attempts, COALESCE(last_error, ''), created_at, updated_at
+	          FROM notification_deliveries`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]*model.NotificationDelivery, 0)
+	for rows.Next() {
+		var d model.NotificationDelivery
+		if err := rows.Scan(
+			&d.ID,
+			&d.ActionLogID,
+			&d.SyncID,
+			&d.ShiftID,
+			&d.UserID,
+			&d.Kind,
+			&d.Channel,
+			&d.Recipient,
+			&d.Status,
+			&d.SlackTS,
+			&d.Attempts,
+			&d.LastError,
+			&d.CreatedAt,
+			&d.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan notification delivery: %w", err)
+		}
+		deliveries = append(deliveries, &d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return deliveries, nil
+}
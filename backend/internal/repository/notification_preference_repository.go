@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"seeft-slack-notification/internal/model"
+)
+
+type NotificationPreferenceRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationPreferenceRepository(db *sql.DB) *NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{db: db}
+}
+
+const notificationPreferenceColumns = `user_id, notify_create, notify_update, notify_delete,
+	delivery_channel, delivery_mode, include_inactive_weather, within_hours, last_digest_at, updated_at`
+
+// scanNotificationPreference 1行分を構造体に詰め替える
+func scanNotificationPreference(scanner interface{ Scan(...interface{}) error }) (*model.NotificationPreference, error) {
+	var p model.NotificationPreference
+	err := scanner.Scan(
+		&p.UserID,
+		&p.NotifyCreate,
+		&p.NotifyUpdate,
+		&p.NotifyDelete,
+		&p.DeliveryChannel,
+		&p.DeliveryMode,
+		&p.IncludeInactiveWeather,
+		&p.WithinHours,
+		&p.LastDigestAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetByUserID ユーザーの通知設定を取得（未設定ならnil）
+func (r *NotificationPreferenceRepository) GetByUserID(userID int) (*model.NotificationPreference, error) {
+	query := `SELECT ` + notificationPreferenceColumns + `
+	          FROM notification_preferences WHERE user_id = $1`
+
+	p, err := scanNotificationPreference(r.db.QueryRow(query, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preference: %w", err)
+	}
+	return p, nil
+}
+
+// GetAll 保存済みの通知設定を全件取得し、ユーザーID -> 設定 のマップで返す
+func (r *NotificationPreferenceRepository) GetAll() (map[int]*model.NotificationPreference, error) {
+	query := `SELECT ` + notificationPreferenceColumns + ` FROM notification_preferences`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification preferences: %w", err)
+	}
+	defer rows.Close()
+
+	prefs := make(map[int]*model.NotificationPreference)
+	for rows.Next() {
+		p, err := scanNotificationPreference(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification preference: %w", err)
+		}
+		prefs[p.UserID] = p
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return prefs, nil
+}
+
+// GetDigestUsers まとめ送信(digest)を選んでいるユーザーの設定を取得
+func (r *NotificationPreferenceRepository) GetDigestUsers() ([]*model.NotificationPreference, error) {
+	query := `SELECT ` + notificationPreferenceColumns + `
+	          FROM notification_preferences
+	          WHERE delivery_mode = $1 AND delivery_channel <> $2`
+
+	rows, err := r.db.Query(query, model.DeliveryModeDigest, model.DeliveryChannelNone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query digest users: %w", err)
+	}
+	defer rows.Close()
+
+	var prefs []*model.NotificationPreference
+	for rows.Next() {
+		p, err := scanNotificationPreference(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification preference: %w", err)
+		}
+		prefs = append(prefs, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return prefs, nil
+}
+
+// Upsert 通知設定を保存する（新規なら作成、既存なら上書き）
+func (r *NotificationPreferenceRepository) Upsert(p *model.NotificationPreference) error {
+	query := `
+        INSERT INTO notification_preferences
+            (user_id, notify_create, notify_update, notify_delete,
+             delivery_channel, delivery_mode, include_inactive_weather, within_hours)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        ON CONFLICT (user_id)
+        DO UPDATE SET
+            notify_create = EXCLUDED.notify_create,
+            notify_update = EXCLUDED.notify_update,
+            notify_delete = EXCLUDED.notify_delete,
+            delivery_channel = EXCLUDED.delivery_channel,
+            delivery_mode = EXCLUDED.delivery_mode,
+            include_inactive_weather = EXCLUDED.include_inactive_weather,
+            within_hours = EXCLUDED.within_hours,
+            updated_at = CURRENT_TIMESTAMP
+        RETURNING last_digest_at, updated_at`
+
+	err := r.db.QueryRow(query,
+		p.UserID,
+		p.NotifyCreate,
+		p.NotifyUpdate,
+		p.NotifyDelete,
+		p.DeliveryChannel,
+		p.DeliveryMode,
+		p.IncludeInactiveWeather,
+		p.WithinHours,
+	).Scan(&p.LastDigestAt, &p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert notification preference: %w", err)
+	}
+
+	return nil
+}
+
+// MarkDigestSent まとめ送信の完了時刻を記録する
+func (r *NotificationPreferenceRepository) MarkDigestSent(userID int, sentAt time.Time) error {
+	query := `UPDATE notification_preferences SET last_digest_at = $1 WHERE user_id = $2`
+	if _, err := r.db.Exec(query, sentAt.UTC(), userID); err != nil {
+		return fmt.Errorf("failed to mark digest sent: %w", err)
+	}
+	return nil
+}
@@ -1,6 +1,12 @@
 package repository
 
-import "database/sql"
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
 
 type ShiftReadRepository struct {
 	db *sql.DB
@@ -22,3 +28,67 @@ func (r *ShiftReadRepository) Upsert(tx *sql.Tx, shiftID, userID int, isRead boo
 	_, err := tx.Exec(query, shiftID, userID, isRead)
 	return err
 }
+
+// MarkRead 指定したシフトを既読にし、既読にした日時を返す
+func (r *ShiftReadRepository) MarkRead(shiftID, userID int) (time.Time, error) {
+	query := `
+        INSERT INTO shift_reads (shift_id, user_id, is_read, read_at)
+        VALUES ($1, $2, TRUE, CURRENT_TIMESTAMP)
+        ON CONFLICT (shift_id, user_id)
+        DO UPDATE SET is_read = TRUE, read_at = EXCLUDED.read_at, updated_at = CURRENT_TIMESTAMP
+        RETURNING read_at`
+
+	var readAt time.Time
+	if err := r.db.QueryRow(query, shiftID, userID).Scan(&readAt); err != nil {
+		return time.Time{}, fmt.Errorf("failed to mark shift read: %w", err)
+	}
+	return readAt, nil
+}
+
+// MarkReadBulk ユーザーのシフトをまとめて既読にし、件数を返す
+// shiftIDs が空でなければそのシフトのみ（他人のシフトは含めない）、
+// before が指定されていれば before 以前の変更しかないシフトのみを対象にする
+// action_log.created_at はセッションのタイムゾーンでの CURRENT_TIMESTAMP なので、
+// before は timestamptz として渡し、比較時に同じタイムゾーンへ変換させる
+func (r *ShiftReadRepository) MarkReadBulk(userID int, shiftIDs []int, before *time.Time) (int, error) {
+	query := `
+        INSERT INTO shift_reads (shift_id, user_id, is_read, read_at)
+        SELECT s.id, s.user_id, TRUE, CURRENT_TIMESTAMP
+        FROM shifts s
+        WHERE s.user_id = $1
+          AND ($2::int[] IS NULL OR cardinality($2::int[]) = 0 OR s.id = ANY($2))
+          AND ($3::timestamptz IS NULL OR NOT EXISTS (
+              SELECT 1 FROM action_log a WHERE a.shift_id = s.id AND a.created_at > $3::timestamptz
+          ))
+        ON CONFLICT (shift_id, user_id)
+        DO UPDATE SET is_read = TRUE, read_at = EXCLUDED.read_at, updated_at = CURRENT_TIMESTAMP`
+
+	result, err := r.db.Exec(query, userID, pq.Array(shiftIDs), before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark shifts read: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(n), nil
+}
+
+// CountUnread ユーザーの未読の変更（通知一覧の未読）の件数
+func (r *ShiftReadRepository) CountUnread(userID int) (int, error) {
+	query := `
+        SELECT COUNT(*)
+        FROM action_log a
+        JOIN shifts s ON s.id = a.shift_id
+        LEFT JOIN shift_reads sr ON sr.shift_id = a.shift_id AND sr.user_id = s.user_id
+        WHERE s.user_id = $1
+          AND a.action_type IN ('CREATE', 'UPDATE', 'DELETE')
+          AND NOT COALESCE(sr.read_at >= a.created_at, FALSE)`
+
+	var count int
+	if err := r.db.QueryRow(query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unread: %w", err)
+	}
+	return count, nil
+}
@@ -3,8 +3,11 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"seeft-slack-notification/internal/model"
+
+	"github.com/lib/pq"
 )
 
 type ShiftRepository struct {
@@ -200,3 +203,385 @@ func (r *ShiftRepository) GetByUserID(userID int) ([]*model.ShiftWithReadStatus,
 
 	return shifts, nil
 }
+
+// GetByID IDでシフトを取得（削除済みも含む）。存在しない場合は nil を返す
+func (r *ShiftRepository) GetByID(id int) (*model.Shift, error) {
+	query := `SELECT id, year_id, time_id, date, weather, user_id, task_name, created_at, updated_at, deleted_at
+	          FROM shifts WHERE id = $1`
+
+	var shift model.Shift
+	err := r.db.QueryRow(query, id).Scan(
+		&shift.ID,
+		&shift.YearID,
+		&shift.TimeID,
+		&shift.Date,
+		&shift.Weather,
+		&shift.UserID,
+		&shift.TaskName,
+		&shift.CreatedAt,
+		&shift.UpdatedAt,
+		&shift.DeletedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shift: %w", err)
+	}
+
+	return &shift, nil
+}
+
+// GetOwners シフトID -> 担当ユーザーID（削除済みも含む。存在しないIDは含まれない）
+func (r *ShiftRepository) GetOwners(ids []int) (map[int]int, error) {
+	rows, err := r.db.Query(`SELECT id, user_id FROM shifts WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shift owners: %w", err)
+	}
+	defer rows.Close()
+
+	owners := make(map[int]int, len(ids))
+	for rows.Next() {
+		var id, userID int
+		if err := rows.Scan(&id, &userID); err != nil {
+			return nil, fmt.Errorf("failed to scan shift owner: %w", err)
+		}
+		owners[id] = userID
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return owners, nil
+}
+
+// GetGrid 日付・天気ごとの有効なシフトを、ユーザー名・時間順に取得（スケジュール表用）
+func (r *ShiftRepository) GetGrid(filter model.ScheduleGridFilter) ([]*model.GridShift, error) {
+	query := `
+        SELECT s.user_id, u.name, u.user_group, s.time_id, s.task_name
+        FROM shifts s
+        JOIN users u ON u.id = s.user_id
+        WHERE s.deleted_at IS NULL
+            AND s.year_id = $1
+            AND s.date = $2
+            AND s.weather = $3`
+	args := []interface{}{filter.YearID, filter.Date, filter.Weather}
+
+	if filter.Task != "" {
+		args = append(args, filter.Task)
+		query += fmt.Sprintf(" AND s.task_name = $%d", len(args))
+	}
+	if filter.Group != "" {
+		args = append(args, filter.Group)
+		query += fmt.Sprintf(" AND u.user_group = $%d", len(args))
+	}
+	query += " ORDER BY u.name ASC, s.user_id ASC, s.time_id ASC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedule grid: %w", err)
+	}
+	defer rows.Close()
+
+	shifts := make([]*model.GridShift, 0)
+	for rows.Next() {
+		var g model.GridShift
+		if err := rows.Scan(&g.UserID, &g.UserName, &g.UserGroup, &g.TimeID, &g.TaskName); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule grid: %w", err)
+		}
+		shifts = append(shifts, &g)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return shifts, nil
+}
+
+// shiftSortColumns 並び替えキー -> 列（ORDER BY に値を埋め込まないよう、ここにあるものだけを使う）
+var shiftSortColumns = map[string]string{
+	model.ShiftSortDate:    "s.date",
+	model.ShiftSortTime:    "s.time_id",
+	model.ShiftSortUser:    "u.name",
+	model.ShiftSortTask:    "s.task_name",
+	model.ShiftSortWeather: "s.weather",
+	model.ShiftSortYear:    "s.year_id",
+}
+
+// likeEscaper LIKE のワイルドカードを文字として扱うためのエスケープ
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Search 条件に一致する有効なシフトの1ページ分と、条件に一致する全件数を取得
+func (r *ShiftRepository) Search(filter model.ShiftSearchFilter) ([]*model.ShiftSearchResult, int, error) {
+	where := []string{"s.deleted_at IS NULL"}
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.YearID != 0 {
+		add("s.year_id = $%d", filter.YearID)
+	}
+	if filter.UserName != "" {
+		add("u.name ILIKE $%d", "%"+likeEscaper.Replace(filter.UserName)+"%")
+	}
+	if filter.Task != "" {
+		pattern := likeEscaper.Replace(filter.Task) + "%"
+		if filter.TaskMatch != model.TaskMatchPrefix {
+			pattern = "%" + pattern
+		}
+		add("s.task_name ILIKE $%d", pattern)
+	}
+	if filter.Date != "" {
+		add("s.date = $%d", filter.Date)
+	}
+	if filter.Weather != "" {
+		add("s.weather = $%d", filter.Weather)
+	}
+	if filter.FromTimeID != 0 {
+		add("s.time_id >= $%d", filter.FromTimeID)
+	}
+	if filter.ToTimeID != 0 {
+		add("s.time_id < $%d", filter.ToTimeID)
+	}
+
+	from := `
+        FROM shifts s
+        JOIN users u ON u.id = s.user_id
+        WHERE ` + strings.Join(where, " AND ")
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*)"+from, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count shifts: %w", err)
+	}
+
+	// 指定が無い部分は日付・時間・ユーザー名の順。最後に id で並びを一意にする（ページ間で重複・欠落させない）
+	var order []string
+	for _, o := range filter.Sort {
+		column, ok := shiftSortColumns[o.Field]
+		if !ok {
+			return nil, 0, fmt.Errorf("unknown sort field: %s", o.Field)
+		}
+		if o.Desc {
+			column += " DESC"
+		}
+		order = append(order, column)
+	}
+	order = append(order, "s.date", "s.time_id", "u.name", "s.id")
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := `
+        SELECT s.id, s.year_id, s.time_id, s.date, s.weather, s.user_id, s.task_name,
+            s.created_at, s.updated_at, s.deleted_at, u.name, u.user_group` + from + `
+        ORDER BY ` + strings.Join(order, ", ") +
+		fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search shifts: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]*model.ShiftSearchResult, 0)
+	for rows.Next() {
+		s := &model.Shift{}
+		result := &model.ShiftSearchResult{Shift: s}
+		err := rows.Scan(
+			&s.ID,
+			&s.YearID,
+			&s.TimeID,
+			&s.Date,
+			&s.Weather,
+			&s.UserID,
+			&s.TaskName,
+			&s.CreatedAt,
+			&s.UpdatedAt,
+			&s.DeletedAt,
+			&result.UserName,
+			&result.UserGroup,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan shift: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows error: %w", err)
+	}
+
+	return results, total, nil
+}
+
+// GetTaskSummaries 有効なシフトのタスクごとの枠数・人数（yearID=0 なら全年度）
+func (r *ShiftRepository) GetTaskSummaries(yearID int) ([]*model.TaskSummary, error) {
+	query := `
+        SELECT task_name, COUNT(*), COUNT(DISTINCT user_id)
+        FROM shifts
+        WHERE deleted_at IS NULL AND ($1 = 0 OR year_id = $1)
+        GROUP BY task_name
+        ORDER BY task_name ASC`
+
+	rows, err := r.db.Query(query, yearID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task summaries: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := make([]*model.TaskSummary, 0)
+	for rows.Next() {
+		var t model.TaskSummary
+		if err := rows.Scan(&t.TaskName, &t.SlotCount, &t.UserCount); err != nil {
+			return nil, fmt.Errorf("failed to scan task summary: %w", err)
+		}
+		summaries = append(summaries, &t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// GetByTask タスクの有効なシフトを、日付・天気・時間・ユーザー名順に取得（yearID=0 なら全年度）
+func (r *ShiftRepository) GetByTask(taskName string, yearID int) ([]*model.TaskShift, error) {
+	query := `
+        SELECT s.year_id, s.date, s.weather, s.time_id, s.user_id, u.name
+        FROM shifts s
+        JOIN users u ON u.id = s.user_id
+        WHERE s.deleted_at IS NULL
+            AND s.task_name = $1
+            AND ($2 = 0 OR s.year_id = $2)
+        ORDER BY s.year_id ASC, s.date ASC, s.weather ASC, s.time_id ASC, u.name ASC, s.user_id ASC`
+
+	rows, err := r.db.Query(query, taskName, yearID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shifts for task %s: %w", taskName, err)
+	}
+	defer rows.Close()
+
+	shifts := make([]*model.TaskShift, 0)
+	for rows.Next() {
+		var t model.TaskShift
+		if err := rows.Scan(&t.YearID, &t.Date, &t.Weather, &t.TimeID, &t.UserID, &t.UserName); err != nil {
+			return nil, fmt.Errorf("failed to scan task shift: %w", err)
+		}
+		shifts = append(shifts, &t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return shifts, nil
+}
+
+// GetAllByUserID 指定したユーザーのシフトを削除済みも含めて、年度・日付・天気・時間順に取得（カレンダー用）
+func (r *ShiftRepository) GetAllByUserID(userID int) ([]*model.Shift, error) {
+	query := `SELECT id, year_id, time_id, date, weather, user_id, task_name, created_at, updated_at, deleted_at
+	          FROM shifts
+	          WHERE user_id = $1
+	          ORDER BY year_id ASC, date ASC, weather ASC, time_id ASC, id ASC`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shifts for user %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	shifts := make([]*model.Shift, 0)
+	for rows.Next() {
+		var shift model.Shift
+		err := rows.Scan(
+			&shift.ID,
+			&shift.YearID,
+			&shift.TimeID,
+			&shift.Date,
+			&shift.Weather,
+			&shift.UserID,
+			&shift.TaskName,
+			&shift.CreatedAt,
+			&shift.UpdatedAt,
+			&shift.DeletedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shift: %w", err)
+		}
+		shifts = append(shifts, &shift)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return shifts, nil
+}
+
+// LockByID トランザクション内で有効なシフトを取得し、コミットまで他の更新を待たせる
+// 存在しない・削除済みの場合は nil を返す
+func (r *ShiftRepository) LockByID(tx *sql.Tx, id int) (*model.Shift, error) {
+	query := `SELECT id, year_id, time_id, date, weather, user_id, task_name, created_at, updated_at, deleted_at
+	          FROM shifts WHERE id = $1 AND deleted_at IS NULL
+	          FOR UPDATE`
+
+	var shift model.Shift
+	err := tx.QueryRow(query, id).Scan(
+		&shift.ID,
+		&shift.YearID,
+		&shift.TimeID,
+		&shift.Date,
+		&shift.Weather,
+		&shift.UserID,
+		&shift.TaskName,
+		&shift.CreatedAt,
+		&shift.UpdatedAt,
+		&shift.DeletedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock shift: %w", err)
+	}
+	return &shift, nil
+}
+
+// ExistsActive ユーザーがその枠に有効なシフトを持っているか
+func (r *ShiftRepository) ExistsActive(q DBTX, yearID, timeID int, date, weather string, userID int) (bool, error) {
+	query := `SELECT EXISTS (
+	              SELECT 1 FROM shifts
+	              WHERE year_id = $1 AND time_id = $2 AND date = $3 AND weather = $4 AND user_id = $5
+	                AND deleted_at IS NULL
+	          )`
+
+	var exists bool
+	if err := q.QueryRow(query, yearID, timeID, date, weather, userID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check shift: %w", err)
+	}
+	return exists, nil
+}
+
+// Assign ユーザーをその枠に割り当てる
+// 同じ枠の削除済みのシフトがあれば、新しく作らずにそれを復活させる（ユニークキーが削除済みの行も含むため）
+func (r *ShiftRepository) Assign(tx *sql.Tx, shift *model.Shift) error {
+	query := `INSERT INTO shifts (year_id, time_id, date, weather, user_id, task_name)
+	          VALUES ($1, $2, $3, $4, $5, $6)
+	          ON CONFLICT (year_id, time_id, date, weather, user_id)
+	          DO UPDATE SET task_name = EXCLUDED.task_name, deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+	          RETURNING id, created_at, updated_at`
+
+	err := tx.QueryRow(query, shift.YearID, shift.TimeID, shift.Date, shift.Weather, shift.UserID, shift.TaskName).Scan(
+		&shift.ID,
+		&shift.CreatedAt,
+		&shift.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to assign shift: %w", err)
+	}
+	return nil
+}
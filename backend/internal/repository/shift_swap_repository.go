@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"seeft-slack-notification/internal/model"
+
+	"github.com/lib/pq"
+)
+
+type ShiftSwapRepository struct {
+	db *sql.DB
+}
+
+func NewShiftSwapRepository(db *sql.DB) *ShiftSwapRepository {
+	return &ShiftSwapRepository{db: db}
+}
+
+// shiftSwapSelect 申請者・相手の名前と、それぞれの枠を JOIN した一覧用のクエリ
+const shiftSwapSelect = `
+        SELECT w.id, w.status, w.message, w.approver_id, w.approved_tasks, w.sync_id, w.exported_at, w.created_at, w.updated_at,
+               w.requester_id, ru.name, w.requester_shift_id, rs.year_id, rs.time_id, rs.date, rs.weather, w.requester_task_name,
+               w.counterpart_id, cu.name, w.counterpart_shift_id, cs.year_id, cs.time_id, cs.date, cs.weather, w.counterpart_task_name
+        FROM shift_swaps w
+        JOIN users ru ON ru.id = w.requester_id
+        JOIN users cu ON cu.id = w.counterpart_id
+        JOIN shifts rs ON rs.id = w.requester_shift_id
+        JOIN shifts cs ON cs.id = w.counterpart_shift_id`
+
+// scanShiftSwap shiftSwapSelect の1行を読み込む
+func scanShiftSwap(row interface{ Scan(...interface{}) error }) (*model.ShiftSwap, error) {
+	var w model.ShiftSwap
+	r, c := &w.Requester, &w.Counterpart
+	err := row.Scan(
+		&w.ID, &w.Status, &w.Message, &w.ApproverID, pq.Array(&w.ApprovedTasks), &w.SyncID, &w.ExportedAt, &w.CreatedAt, &w.UpdatedAt,
+		&r.UserID, &r.UserName, &r.ShiftID, &r.YearID, &r.TimeID, &r.Date, &r.Weather, &r.TaskName,
+		&c.UserID, &c.UserName, &c.ShiftID, &c.YearID, &c.TimeID, &c.Date, &c.Weather, &c.TaskName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// querySwaps 一覧をクエリして ShiftSwap の一覧に詰め替える
+func (r *ShiftSwapRepository) querySwaps(query string, args ...interface{}) ([]*model.ShiftSwap, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shift swaps: %w", err)
+	}
+	defer rows.Close()
+
+	swaps := make([]*model.ShiftSwap, 0)
+	for rows.Next() {
+		w, err := scanShiftSwap(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shift swap: %w", err)
+		}
+		swaps = append(swaps, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return swaps, nil
+}
+
+// Create 交換の申請を保存する（ID・日時・状態がセットされる）
+func (r *ShiftSwapRepository) Create(w *model.ShiftSwap) error {
+	query := `
+        INSERT INTO shift_swaps (requester_id, requester_shift_id, requester_task_name,
+                                 counterpart_id, counterpart_shift_id, counterpart_task_name, message)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id, status, created_at, updated_at`
+
+	err := r.db.QueryRow(query,
+		w.Requester.UserID, w.Requester.ShiftID, w.Requester.TaskName,
+		w.Counterpart.UserID, w.Counterpart.ShiftID, w.Counterpart.TaskName, w.Message,
+	).Scan(&w.ID, &w.Status, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create shift swap: %w", err)
+	}
+	return nil
+}
+
+// GetByID IDで交換の申請を取得
+func (r *ShiftSwapRepository) GetByID(id int) (*model.ShiftSwap, error) {
+	w, err := scanShiftSwap(r.db.QueryRow(shiftSwapSelect+` WHERE w.id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("shift swap %w: id=%d", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shift swap: %w", err)
+	}
+	return w, nil
+}
+
+// List 条件に一致する交換の申請を新しい順に取得
+func (r *ShiftSwapRepository) List(filter model.ShiftSwapFilter) ([]*model.ShiftSwap, error) {
+	query := shiftSwapSelect + `
+        WHERE ($1 = 0 OR w.requester_id = $1 OR w.counterpart_id = $1)
+          AND ($2 = '' OR w.status = $2)
+        ORDER BY w.created_at DESC, w.id DESC`
+
+	return r.querySwaps(query, filter.UserID, filter.Status)
+}
+
+// GetUnexported 承認済みで、まだシートに書き戻していない交換を古い順に取得
+func (r *ShiftSwapRepository) GetUnexported() ([]*model.ShiftSwap, error) {
+	query := shiftSwapSelect + `
+        WHERE w.status = 'approved' AND w.exported_at IS NULL
+        ORDER BY w.updated_at ASC, w.id ASC`
+
+	return r.querySwaps(query)
+}
+
+// HasOpen いずれかのシフトが、まだ結論の出ていない交換 (pending / accepted) に含まれているか
+func (r *ShiftSwapRepository) HasOpen(shiftIDs []int) (bool, error) {
+	query := `
+        SELECT EXISTS (
+            SELECT 1 FROM shift_swaps
+            WHERE status IN ('pending', 'accepted')
+              AND (requester_shift_id = ANY($1) OR counterpart_shift_id = ANY($1))
+        )`
+
+	var exists bool
+	if err := r.db.QueryRow(query, pq.Array(shiftIDs)).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check open shift swaps: %w", err)
+	}
+	return exists, nil
+}
+
+// Transition 状態が from のときだけ to に変える
+// 他の操作で既に状態が変わっていた場合は ErrConflict を返す
+func (r *ShiftSwapRepository) Transition(q DBTX, id int, from, to string, approverID *int) error {
+	query := `
+        UPDATE shift_swaps
+        SET status = $3, approver_id = COALESCE($4, approver_id), updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1 AND status = $2`
+
+	result, err := q.Exec(query, id, from, to, approverID)
+	if err != nil {
+		return fmt.Errorf("failed to update shift swap: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("shift swap is no longer %s: %w", from, ErrConflict)
+	}
+	return nil
+}
+
+// AddApprovals 承認待ち (accepted) の交換に、承認を済ませたタスクを加え、加えた後の一覧を返す
+// 既に承認待ちでなくなっていた場合は ErrConflict を返す
+func (r *ShiftSwapRepository) AddApprovals(tx *sql.Tx, id int, tasks []string) ([]string, error) {
+	query := `
+        UPDATE shift_swaps
+        SET approved_tasks = ARRAY(SELECT DISTINCT unnest(approved_tasks || $2::text[]) ORDER BY 1),
+            updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1 AND status = 'accepted'
+        RETURNING approved_tasks`
+
+	var approved []string
+	err := tx.QueryRow(query, id, pq.Array(tasks)).Scan(pq.Array(&approved))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("shift swap is no longer accepted: %w", ErrConflict)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve shift swap: %w", err)
+	}
+	return approved, nil
+}
+
+// SetSyncID 反映したときの変更履歴のまとまりを記録する
+func (r *ShiftSwapRepository) SetSyncID(tx *sql.Tx, id, syncID int) error {
+	if _, err := tx.Exec(`UPDATE shift_swaps SET sync_id = $1 WHERE id = $2`, syncID, id); err != nil {
+		return fmt.Errorf("failed to set shift swap sync id: %w", err)
+	}
+	return nil
+}
+
+// MarkExported シートへの書き戻しが済んだ交換に印を付け、件数を返す（承認済みのもののみ）
+func (r *ShiftSwapRepository) MarkExported(ids []int) (int, error) {
+	query := `
+        UPDATE shift_swaps SET exported_at = CURRENT_TIMESTAMP
+        WHERE id = ANY($1) AND status = 'approved' AND exported_at IS NULL`
+
+	result, err := r.db.Exec(query, pq.Array(ids))
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark shift swaps exported: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(n), nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"seeft-slack-notification/internal/model"
+)
+
+type StaffingRequirementRepository struct {
+	db *sql.DB
+}
+
+func NewStaffingRequirementRepository(db *sql.DB) *StaffingRequirementRepository {
+	return &StaffingRequirementRepository{db: db}
+}
+
+// GetAll 全ての必要人数を取得
+func (r *StaffingRequirementRepository) GetAll() ([]*model.StaffingRequirement, error) {
+	query := `SELECT id, year_id, task_name, date, weather, time_id, min_staff, updated_at
+	          FROM staffing_requirements
+	          ORDER BY task_name ASC, year_id ASC, date ASC, weather ASC, time_id ASC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query staffing requirements: %w", err)
+	}
+	defer rows.Close()
+
+	reqs := make([]*model.StaffingRequirement, 0)
+	for rows.Next() {
+		var req model.StaffingRequirement
+		if err := rows.Scan(
+			&req.ID,
+			&req.YearID,
+			&req.TaskName,
+			&req.Date,
+			&req.Weather,
+			&req.TimeID,
+			&req.MinStaff,
+			&req.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan staffing requirement: %w", err)
+		}
+		reqs = append(reqs, &req)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return reqs, nil
+}
+
+// ReplaceAll 必要人数を全て入れ替える（スプレッドシートの内容で上書き）
+func (r *StaffingRequirementRepository) ReplaceAll(tx *sql.Tx, items []model.StaffingRequirementItem) error {
+	if _, err := tx.Exec(`DELETE FROM staffing_requirements`); err != nil {
+		return fmt.Errorf("failed to clear staffing requirements: %w", err)
+	}
+
+	query := `INSERT INTO staffing_requirements (year_id, task_name, date, weather, time_id, min_staff)
+	          VALUES ($1, $2, $3, $4, $5, $6)
+	          ON CONFLICT (year_id, task_name, date, weather, time_id)
+	          DO UPDATE SET min_staff = EXCLUDED.min_staff, updated_at = CURRENT_TIMESTAMP`
+
+	for _, item := range items {
+		if _, err := tx.Exec(query, item.YearID, item.TaskName, item.Date, item.Weather, item.TimeID, item.MinStaff); err != nil {
+			return fmt.Errorf("failed to insert staffing requirement: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetCoverage 必要人数ごとの現在の配置人数（必要人数と同じ年度の有効なシフトを数える）
+func (r *StaffingRequirementRepository) GetCoverage() ([]*model.StaffingCoverage, error) {
+	query := `
+        SELECT r.year_id, r.task_name, r.date, r.weather, r.time_id, r.min_staff, COUNT(s.id) AS assigned
+        FROM staffing_requirements r
+        LEFT JOIN shifts s
+            ON s.year_id = r.year_id
+            AND s.task_name = r.task_name
+            AND s.date = r.date
+            AND s.weather = r.weather
+            AND s.time_id = r.time_id
+            AND s.deleted_at IS NULL
+        GROUP BY r.id, r.year_id, r.task_name, r.date, r.weather, r.time_id, r.min_staff
+        ORDER BY r.task_name ASC, r.year_id ASC, r.date ASC, r.weather ASC, r.time_id ASC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query staffing coverage: %w", err)
+	}
+	defer rows.Close()
+
+	coverages := make([]*model.StaffingCoverage, 0)
+	for rows.Next() {
+		var c model.StaffingCoverage
+		if err := rows.Scan(&c.YearID, &c.TaskName, &c.Date, &c.Weather, &c.TimeID, &c.MinStaff, &c.Assigned); err != nil {
+			return nil, fmt.Errorf("failed to scan staffing coverage: %w", err)
+		}
+		coverages = append(coverages, &c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return coverages, nil
+}
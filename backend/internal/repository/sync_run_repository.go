@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type SyncRunRepository struct {
+	db *sql.DB
+}
+
+func NewSyncRunRepository(db *sql.DB) *SyncRunRepository {
+	return &SyncRunRepository{db: db}
+}
+
+// syncLockKey 変更履歴を書くトランザクションを1つずつにするための advisory lock のキー
+const syncLockKey int64 = 0x5ee7_5ac1
+
+// Create 同期1回分のレコードを作成し、IDを返す
+// 変更履歴 (action_log) を書く処理（同期・交換の反映）はここを通るので、コミットまで advisory lock を取って1つずつにする
+// 並行して書くと action_log のIDの順にコミットされず、IDで続きから読むSSEが後からコミットされた変更を取りこぼす
+func (r *SyncRunRepository) Create(tx *sql.Tx) (int, error) {
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, syncLockKey); err != nil {
+		return 0, fmt.Errorf("failed to lock sync runs: %w", err)
+	}
+
+	var id int
+	if err := tx.QueryRow(`INSERT INTO sync_runs DEFAULT VALUES RETURNING id`).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to create sync run: %w", err)
+	}
+	return id, nil
+}
+
+// Finish 同期で記録した変更件数を保存する
+func (r *SyncRunRepository) Finish(tx *sql.Tx, id, changeCount int) error {
+	if _, err := tx.Exec(`UPDATE sync_runs SET change_count = $1 WHERE id = $2`, changeCount, id); err != nil {
+		return fmt.Errorf("failed to finish sync run: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"seeft-slack-notification/internal/model"
+)
+
+type TaskLeadRepository struct {
+	db *sql.DB
+}
+
+func NewTaskLeadRepository(db *sql.DB) *TaskLeadRepository {
+	return &TaskLeadRepository{db: db}
+}
+
+// GetAll 全てのタスクリーダーを取得
+func (r *TaskLeadRepository) GetAll() ([]*model.TaskLead, error) {
+	query := `SELECT tl.task_name, tl.user_id, u.name, tl.created_at
+	          FROM task_leads tl
+	          JOIN users u ON u.id = tl.user_id
+	          ORDER BY tl.task_name ASC, u.name ASC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task leads: %w", err)
+	}
+	defer rows.Close()
+
+	leads := make([]*model.TaskLead, 0)
+	for rows.Next() {
+		var l model.TaskLead
+		if err := rows.Scan(&l.TaskName, &l.UserID, &l.UserName, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan task lead: %w", err)
+		}
+		leads = append(leads, &l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return leads, nil
+}
+
+// GetTasksByUserID ユーザーがリーダーを務めるタスク名
+func (r *TaskLeadRepository) GetTasksByUserID(userID int) ([]string, error) {
+	query := `SELECT task_name FROM task_leads WHERE user_id = $1 ORDER BY task_name ASC`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query lead tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := make([]string, 0)
+	for rows.Next() {
+		var task string
+		if err := rows.Scan(&task); err != nil {
+			return nil, fmt.Errorf("failed to scan lead task: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// Create タスクリーダーを登録（登録済みなら何もしない）
+func (r *TaskLeadRepository) Create(taskName string, userID int) error {
+	query := `INSERT INTO task_leads (task_name, user_id) VALUES ($1, $2)
+	          ON CONFLICT (task_name, user_id) DO NOTHING`
+
+	if _, err := r.db.Exec(query, taskName, userID); err != nil {
+		if isPQError(err, pgForeignKeyViolation) {
+			return fmt.Errorf("user %w: id=%d", ErrNotFound, userID)
+		}
+		return fmt.Errorf("failed to create task lead: %w", err)
+	}
+	return nil
+}
+
+// Delete タスクリーダーの登録を解除
+func (r *TaskLeadRepository) Delete(taskName string, userID int) error {
+	query := `DELETE FROM task_leads WHERE task_name = $1 AND user_id = $2`
+
+	result, err := r.db.Exec(query, taskName, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete task lead: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("task lead %w", ErrNotFound)
+	}
+
+	return nil
+}
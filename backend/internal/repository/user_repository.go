@@ -2,9 +2,13 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 
 	"seeft-slack-notification/internal/model"
+
+	"github.com/lib/pq"
 )
 
 type UserRepository struct {
@@ -15,58 +19,78 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
-// GetByName ユーザー名でユーザーを取得
-func (r *UserRepository) GetByName(name string) (*model.User, error) {
-	query := `SELECT id, name, slack_user_id, created_at, updated_at 
-	          FROM users WHERE name = $1`
+const userColumns = `id, name, slack_user_id, COALESCE(email, ''), role, user_group, locale, deactivated_at, created_at, updated_at`
 
+// scanUser 1行分を構造体に詰め替える
+func scanUser(scanner interface{ Scan(...interface{}) error }) (*model.User, error) {
 	var user model.User
-	err := r.db.QueryRow(query, name).Scan(
+	err := scanner.Scan(
 		&user.ID,
 		&user.Name,
 		&user.SlackUserID,
+		&user.Email,
+		&user.Role,
+		&user.Group,
+		&user.Locale,
+		&user.DeactivatedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetByName ユーザー名でユーザーを取得
+func (r *UserRepository) GetByName(name string) (*model.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE name = $1`
 
+	user, err := scanUser(r.db.QueryRow(query, name))
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user not found: %s", name)
+		return nil, fmt.Errorf("user %w: %s", ErrNotFound, name)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 // GetByID IDでユーザーを取得
 func (r *UserRepository) GetByID(id int) (*model.User, error) {
-	query := `SELECT id, name, slack_user_id, created_at, updated_at 
-	          FROM users WHERE id = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
 
-	var user model.User
-	err := r.db.QueryRow(query, id).Scan(
-		&user.ID,
-		&user.Name,
-		&user.SlackUserID,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	user, err := scanUser(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user %w: id=%d", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetBySlackUserID SlackのユーザーIDでユーザーを取得（存在しない場合は nil）
+func (r *UserRepository) GetBySlackUserID(slackUserID string) (*model.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE slack_user_id = $1`
 
+	user, err := scanUser(r.db.QueryRow(query, slackUserID))
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user not found: id=%d", id)
+		return nil, nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	return &user, nil
+	return user, nil
 }
 
-// GetAll 全ユーザーを取得する
+// GetAll 全ユーザーを取得する（利用停止したユーザーも含む）
 func (r *UserRepository) GetAll() ([]*model.User, error) {
 	// 1. 全ユーザーを取得するシンプルなクエリ
-	query := `SELECT id, name, slack_user_id, created_at, updated_at FROM users`
+	query := `SELECT ` + userColumns + ` FROM users`
 
 	rows, err := r.db.Query(query)
 	if err != nil {
@@ -78,17 +102,11 @@ func (r *UserRepository) GetAll() ([]*model.User, error) {
 
 	// 2. 1行ずつ取り出してリストに追加
 	for rows.Next() {
-		var u model.User
-		if err := rows.Scan(
-			&u.ID,
-			&u.Name,
-			&u.SlackUserID,
-			&u.CreatedAt,
-			&u.UpdatedAt,
-		); err != nil {
+		u, err := scanUser(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
-		users = append(users, &u)
+		users = append(users, u)
 	}
 
 	// 3. エラーチェック (ループ終了後の確認)
@@ -98,3 +116,233 @@ func (r *UserRepository) GetAll() ([]*model.User, error) {
 
 	return users, nil
 }
+
+// List 条件に一致するユーザーを名前順に取得する
+func (r *UserRepository) List(filter model.UserFilter) ([]*model.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users`
+
+	var conds []string
+	var args []interface{}
+	if filter.Group != "" {
+		args = append(args, filter.Group)
+		conds = append(conds, fmt.Sprintf("user_group = $%d", len(args)))
+	}
+	if !filter.IncludeInactive {
+		conds = append(conds, "deactivated_at IS NULL")
+	}
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY name, id"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*model.User, 0)
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return users, nil
+}
+
+// Create ユーザーを追加し、採番された ID などを u に入れる
+// 名前・SlackのユーザーIDが既存のユーザーと重なる場合は ErrConflict
+func (r *UserRepository) Create(q DBTX, u *model.User) error {
+	if q == nil {
+		q = r.db
+	}
+	query := `
+        INSERT INTO users (name, slack_user_id, email, role, user_group, locale)
+        VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6)
+        RETURNING id, created_at, updated_at
+    `
+
+	err := q.QueryRow(query, u.Name, u.SlackUserID, u.Email, u.Role, u.Group, u.Locale).Scan(&u.ID, &u.CreatedAt, &u.UpdatedAt)
+	if err != nil {
+		if conflict := userConflict(err, u); conflict != nil {
+			return conflict
+		}
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return nil
+}
+
+// Update ユーザーの名前・SlackのユーザーID・メールアドレス・言語・所属グループを u の内容で更新する
+// 名前・SlackのユーザーIDが他のユーザーと重なる場合は ErrConflict
+func (r *UserRepository) Update(q DBTX, u *model.User) error {
+	if q == nil {
+		q = r.db
+	}
+	query := `
+        UPDATE users
+        SET name = $1, slack_user_id = $2, email = NULLIF($3, ''), locale = $4, user_group = $5,
+            updated_at = CURRENT_TIMESTAMP
+        WHERE id = $6
+    `
+
+	result, err := q.Exec(query, u.Name, u.SlackUserID, u.Email, u.Locale, u.Group, u.ID)
+	if err != nil {
+		if conflict := userConflict(err, u); conflict != nil {
+			return conflict
+		}
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user %w: id=%d", ErrNotFound, u.ID)
+	}
+
+	return nil
+}
+
+// Deactivate ユーザーを利用停止にする（停止済みなら停止した日時は変えない）
+func (r *UserRepository) Deactivate(id int) error {
+	query := `
+        UPDATE users
+        SET deactivated_at = COALESCE(deactivated_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1
+    `
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate user: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user %w: id=%d", ErrNotFound, id)
+	}
+
+	return nil
+}
+
+// EmailTaken 他のユーザーが同じメールアドレスを使っているか（大文字・小文字は区別しない）
+func (r *UserRepository) EmailTaken(q DBTX, email string, excludeID int) (bool, error) {
+	if q == nil {
+		q = r.db
+	}
+	query := `SELECT EXISTS (SELECT 1 FROM users WHERE LOWER(email) = LOWER($1) AND id <> $2)`
+
+	var taken bool
+	if err := q.QueryRow(query, email, excludeID).Scan(&taken); err != nil {
+		return false, fmt.Errorf("failed to check email: %w", err)
+	}
+	return taken, nil
+}
+
+// GetAliases 改名前の名前 -> ユーザーID
+func (r *UserRepository) GetAliases() (map[string]int, error) {
+	rows, err := r.db.Query(`SELECT name, user_id FROM user_name_aliases`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user name aliases: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := make(map[string]int)
+	for rows.Next() {
+		var name string
+		var userID int
+		if err := rows.Scan(&name, &userID); err != nil {
+			return nil, fmt.Errorf("failed to scan user name alias: %w", err)
+		}
+		aliases[name] = userID
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return aliases, nil
+}
+
+// GetAliasOwner 改名前の名前として name を持つユーザーのID（無ければ0）
+func (r *UserRepository) GetAliasOwner(q DBTX, name string) (int, error) {
+	if q == nil {
+		q = r.db
+	}
+
+	var userID int
+	err := q.QueryRow(`SELECT user_id FROM user_name_aliases WHERE name = $1`, name).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get user name alias: %w", err)
+	}
+	return userID, nil
+}
+
+// RecordRename 改名前の名前を残す（元の名前に戻した場合は、その名前を改名前の名前から外す）
+func (r *UserRepository) RecordRename(q DBTX, userID int, oldName, newName string) error {
+	if q == nil {
+		q = r.db
+	}
+
+	if _, err := q.Exec(`DELETE FROM user_name_aliases WHERE name = $1 AND user_id = $2`, newName, userID); err != nil {
+		return fmt.Errorf("failed to delete user name alias: %w", err)
+	}
+
+	query := `
+        INSERT INTO user_name_aliases (name, user_id) VALUES ($1, $2)
+        ON CONFLICT (name) DO UPDATE SET user_id = EXCLUDED.user_id, created_at = CURRENT_TIMESTAMP
+    `
+	if _, err := q.Exec(query, oldName, userID); err != nil {
+		return fmt.Errorf("failed to record user name alias: %w", err)
+	}
+	return nil
+}
+
+// UpdateRole ユーザーの権限を変更する
+func (r *UserRepository) UpdateRole(id int, role string) error {
+	query := `UPDATE users SET role = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+
+	result, err := r.db.Exec(query, role, id)
+	if err != nil {
+		return fmt.Errorf("failed to update user role: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user %w: id=%d", ErrNotFound, id)
+	}
+
+	return nil
+}
+
+// userConflict 一意制約違反なら、どの項目が重なったかを付けた ErrConflict にする（それ以外は nil）
+func userConflict(err error, u *model.User) error {
+	if !isPQError(err, pgUniqueViolation) {
+		return nil
+	}
+
+	var pqErr *pq.Error
+	errors.As(err, &pqErr)
+	switch pqErr.Constraint {
+	case "users_name_key":
+		return fmt.Errorf("user name %w: %s", ErrConflict, u.Name)
+	case "users_slack_user_id_key":
+		return fmt.Errorf("slack_user_id %w: %s", ErrConflict, u.SlackUserID)
+	}
+	return fmt.Errorf("user %w", ErrConflict)
+}
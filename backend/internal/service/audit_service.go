@@ -0,0 +1,51 @@
+package service
+
+import (
+	"encoding/json"
+	"log"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/repository"
+)
+
+// AuditService 管理操作を誰が行ったかを記録する
+type AuditService struct {
+	auditRepo *repository.AuditLogRepository
+}
+
+func NewAuditService(auditRepo *repository.AuditLogRepository) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+	}
+}
+
+// Record 操作を記録する（記録に失敗しても操作自体は取り消さない）
+func (s *AuditService) Record(actor *model.Principal, action, target string, status int, detail interface{}) {
+	entry := &model.AuditLog{
+		Action: action,
+		Target: target,
+		Status: status,
+	}
+	if actor != nil {
+		entry.ActorUserID = &actor.UserID
+		entry.ActorName = actor.Name
+		entry.ActorRole = actor.Role
+	}
+	if detail != nil {
+		b, err := json.Marshal(detail)
+		if err != nil {
+			log.Printf("Failed to encode audit detail for %s: %v", action, err)
+		} else {
+			entry.Detail = b
+		}
+	}
+
+	if err := s.auditRepo.Create(nil, entry); err != nil {
+		log.Printf("Failed to record audit log (%s by user %v): %v", action, entry.ActorUserID, err)
+	}
+}
+
+// List 記録を新しい順に返す
+func (s *AuditService) List(filter model.AuditLogFilter) ([]*model.AuditLog, error) {
+	return s.auditRepo.List(filter)
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"seeft-slack-notification/internal/config"
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// loginStateTTL ログイン開始からコールバックまでに許す時間
+const loginStateTTL = 10 * time.Minute
+
+// トークンの発行者（ID トークンやログイン途中の state と取り違えないよう用途ごとに分ける）
+const (
+	authTokenIssuer  = "seeft-slack-notification"
+	loginStateIssuer = "seeft-slack-notification/login"
+)
+
+var (
+	// ErrUnauthenticated トークンが無い・不正・期限切れ
+	ErrUnauthenticated = errors.New("authentication required")
+	// ErrInvalidLoginState ログインの state が一致しない・期限切れ
+	ErrInvalidLoginState = errors.New("invalid or expired login state")
+	// ErrUnknownSlackUser Slack でログインできたが、対応するユーザーが登録されていない
+	ErrUnknownSlackUser = errors.New("slack user is not registered")
+	// ErrUserDeactivated 利用停止されたユーザー
+	ErrUserDeactivated = errors.New("user is deactivated")
+	// ErrForbidden 権限が足りない（lead が担当外のタスクを操作した場合など）
+	ErrForbidden = errors.New("permission denied")
+)
+
+// loginStateClaims ログイン途中の state と nonce（署名付きで cookie に保存する）
+type loginStateClaims struct {
+	jwt.RegisteredClaims
+	State string `json:"state"`
+	Nonce string `json:"nonce"`
+}
+
+// authTokenClaims APIトークンのクレーム（Subject にユーザーIDを入れる）
+// 権限はトークンに入れず、リクエストごとにDBから読む（変更がすぐ反映されるように）
+type authTokenClaims struct {
+	jwt.RegisteredClaims
+	Name        string `json:"name"`
+	SlackUserID string `json:"slack_user_id"`
+}
+
+// AuthService Sign in with Slack でのログインとAPIトークンの発行・検証
+type AuthService struct {
+	provider     *OIDCProvider
+	userRepo     *repository.UserRepository
+	taskLeadRepo *repository.TaskLeadRepository
+	secret       []byte
+	ttl          time.Duration
+}
+
+func NewAuthService(
+	cfg *config.Config,
+	provider *OIDCProvider,
+	userRepo *repository.UserRepository,
+	taskLeadRepo *repository.TaskLeadRepository,
+) *AuthService {
+	return &AuthService{
+		provider:     provider,
+		userRepo:     userRepo,
+		taskLeadRepo: taskLeadRepo,
+		secret:       []byte(cfg.AuthTokenSecret),
+		ttl:          cfg.AuthTokenTTL,
+	}
+}
+
+// LoginURL Slack のログイン画面のURLと、コールバックで照合するための state cookie の値を返す
+func (s *AuthService) LoginURL(ctx context.Context) (string, string, error) {
+	state, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err := s.provider.AuthCodeURL(ctx, state, nonce)
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	cookie, err := s.sign(loginStateClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    loginStateIssuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(loginStateTTL)),
+		},
+		State: state,
+		Nonce: nonce,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return authURL, cookie, nil
+}
+
+// CompleteLogin コールバックの code を交換し、Slack のユーザーに対応するユーザーのトークンを発行する
+func (s *AuthService) CompleteLogin(ctx context.Context, code, state, stateCookie string) (*model.AuthToken, error) {
+	var login loginStateClaims
+	if err := s.parse(stateCookie, loginStateIssuer, &login); err != nil || login.State == "" || login.State != state {
+		return nil, ErrInvalidLoginState
+	}
+
+	identity, err := s.provider.Exchange(ctx, code, login.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetBySlackUserID(identity.SlackUserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSlackUser, identity.SlackUserID)
+	}
+	if !user.Active() {
+		return nil, fmt.Errorf("%w: %s", ErrUserDeactivated, identity.SlackUserID)
+	}
+
+	return s.IssueToken(user)
+}
+
+// IssueToken ユーザーのAPIトークンを発行する
+func (s *AuthService) IssueToken(user *model.User) (*model.AuthToken, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.ttl)
+
+	token, err := s.sign(authTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    authTokenIssuer,
+			Subject:   strconv.Itoa(user.ID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Name:        user.Name,
+		SlackUserID: user.SlackUserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AuthToken{Token: token, ExpiresAt: expiresAt, User: user}, nil
+}
+
+// Authenticate APIトークンを検証して利用者（現在の権限と担当タスク付き）を返す
+func (s *AuthService) Authenticate(token string) (*model.Principal, error) {
+	var claims authTokenClaims
+	if err := s.parse(token, authTokenIssuer, &claims); err != nil {
+		return nil, ErrUnauthenticated
+	}
+
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil || !user.Active() {
+		// トークン発行後に削除・利用停止されたユーザー
+		return nil, ErrUnauthenticated
+	}
+
+	principal := &model.Principal{
+		UserID:      user.ID,
+		Name:        user.Name,
+		SlackUserID: user.SlackUserID,
+		Role:        user.Role,
+	}
+	if user.Role == model.RoleLead {
+		if principal.LeadTasks, err = s.taskLeadRepo.GetTasksByUserID(user.ID); err != nil {
+			return nil, err
+		}
+	}
+	return principal, nil
+}
+
+// sign HS256 で署名する
+func (s *AuthService) sign(claims jwt.Claims) (string, error) {
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, nil
+}
+
+// parse HS256 の署名・有効期限（必須）・発行者を検証する
+func (s *AuthService) parse(raw, issuer string, claims jwt.Claims) error {
+	if raw == "" {
+		return ErrUnauthenticated
+	}
+
+	_, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(issuer),
+		jwt.WithExpirationRequired(),
+	)
+	return err
+}
+
+// randomToken 推測できないランダムな文字列
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
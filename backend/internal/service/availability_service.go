@@ -0,0 +1,191 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/repository"
+)
+
+// maxNGRanges 1回の申告で送れる時間帯の数
+const maxNGRanges = 200
+
+// AvailabilityService 参加できない時間帯(NG)の申告と、割り当てとの突き合わせを行う
+// NGは shifts とは別に持つ（シートの 'NG' とは独立）
+type AvailabilityService struct {
+	db               *sql.DB
+	availabilityRepo *repository.AvailabilityRepository
+}
+
+func NewAvailabilityService(
+	db *sql.DB,
+	availabilityRepo *repository.AvailabilityRepository,
+) *AvailabilityService {
+	return &AvailabilityService{
+		db:               db,
+		availabilityRepo: availabilityRepo,
+	}
+}
+
+// Get ユーザーのNGの申告（yearID が0なら全年度）
+func (s *AvailabilityService) Get(userID, yearID int) (*model.Availability, error) {
+	ranges, err := s.List(model.AvailabilityFilter{UserID: userID, YearID: yearID})
+	if err != nil {
+		return nil, err
+	}
+	return &model.Availability{UserID: userID, YearID: yearID, Ranges: ranges}, nil
+}
+
+// Submit ユーザーのある年度のNGを、送られた内容で置き換える（空なら全て取り消し）
+func (s *AvailabilityService) Submit(userID int, req model.AvailabilityRequest) (*model.Availability, error) {
+	if req.YearID <= 0 {
+		return nil, fmt.Errorf("%w: year_id is required", ErrInvalidInput)
+	}
+	if len(req.Ranges) > maxNGRanges {
+		return nil, fmt.Errorf("%w: ranges must not exceed %d", ErrInvalidInput, maxNGRanges)
+	}
+
+	ranges := make([]*model.NGRange, 0, len(req.Ranges))
+	for i, in := range req.Ranges {
+		ng, err := parseNGRange(req.YearID, in)
+		if err != nil {
+			return nil, fmt.Errorf("ranges[%d]: %w", i, err)
+		}
+		ranges = append(ranges, ng)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.availabilityRepo.ReplaceForUser(tx, userID, req.YearID, ranges); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s.Get(userID, req.YearID)
+}
+
+// List 条件に一致するNGの一覧（管理者・GAS向け）
+func (s *AvailabilityService) List(filter model.AvailabilityFilter) ([]*model.NGRange, error) {
+	ranges, err := s.availabilityRepo.List(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ng := range ranges {
+		ng.Start = timeIDToString(ng.StartTimeID)
+		ng.End = timeIDToString(ng.EndTimeID)
+	}
+	return ranges, nil
+}
+
+// Slots NGの時間帯を枠ごとのセルに展開する（GASがシートに 'NG' を書き込む用、同期と同じ形）
+func (s *AvailabilityService) Slots(filter model.AvailabilityFilter) ([]model.ShiftChange, error) {
+	ranges, err := s.availabilityRepo.List(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// 重なった時間帯は同じ枠を1つにまとめる
+	seen := make(map[string]bool)
+	cells := make([]model.ShiftChange, 0)
+	for _, ng := range ranges {
+		for timeID := ng.StartTimeID; timeID < ng.EndTimeID; timeID++ {
+			key := fmt.Sprintf("%d-%d-%s-%s-%d", ng.YearID, timeID, ng.Date, ng.Weather, ng.UserID)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			cells = append(cells, model.ShiftChange{
+				YearID:   ng.YearID,
+				TimeID:   timeID,
+				Date:     ng.Date,
+				Weather:  ng.Weather,
+				UserName: ng.UserName,
+				TaskName: model.NGTaskName,
+			})
+		}
+	}
+	return cells, nil
+}
+
+// Conflicts 同期するシフトのうち、NGを申告した枠にタスクが割り当てられているもの
+// 同期を止めないよう、NGを読めなかった場合はログに残して何も返さない
+func (s *AvailabilityService) Conflicts(changes []model.ShiftChange) []*model.NGConflict {
+	ranges, err := s.availabilityRepo.List(model.AvailabilityFilter{})
+	if err != nil {
+		log.Printf("Failed to load availability: %v", err)
+		return []*model.NGConflict{}
+	}
+
+	// ユーザー名・年度・日付・天気 -> その日のNG
+	byDay := make(map[string][]*model.NGRange)
+	for _, ng := range ranges {
+		key := fmt.Sprintf("%s-%d-%s-%s", ng.UserName, ng.YearID, ng.Date, ng.Weather)
+		byDay[key] = append(byDay[key], ng)
+	}
+
+	conflicts := make([]*model.NGConflict, 0)
+	for _, c := range changes {
+		if !isStaffedTask(c.TaskName) {
+			continue
+		}
+
+		key := fmt.Sprintf("%s-%d-%s-%s", c.UserName, c.YearID, c.Date, c.Weather)
+		for _, ng := range byDay[key] {
+			if ng.Covers(c.YearID, c.TimeID, c.Date, c.Weather) {
+				conflicts = append(conflicts, &model.NGConflict{
+					YearID:   c.YearID,
+					TimeID:   c.TimeID,
+					Date:     c.Date,
+					Weather:  c.Weather,
+					UserName: c.UserName,
+					TaskName: c.TaskName,
+					Note:     ng.Note,
+				})
+				break
+			}
+		}
+	}
+	return conflicts
+}
+
+// parseNGRange 申告された時間帯を検証して NGRange にする
+func parseNGRange(yearID int, in model.NGRangeInput) (*model.NGRange, error) {
+	date := strings.TrimSpace(in.Date)
+	weather := strings.TrimSpace(in.Weather)
+	if date == "" || weather == "" {
+		return nil, fmt.Errorf("%w: date and weather are required", ErrInvalidInput)
+	}
+
+	start, err := ClockToTimeID(in.From)
+	if err != nil {
+		return nil, err
+	}
+	end, err := ClockToTimeID(in.To)
+	if err != nil {
+		return nil, err
+	}
+	if start >= end {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidInput)
+	}
+
+	return &model.NGRange{
+		YearID:      yearID,
+		Date:        date,
+		Weather:     weather,
+		StartTimeID: start,
+		EndTimeID:   end,
+		Note:        strings.TrimSpace(in.Note),
+	}, nil
+}
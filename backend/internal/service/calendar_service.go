@@ -0,0 +1,241 @@
+package service
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/repository"
+)
+
+// ErrInvalidCalendarToken カレンダーのトークンが無い・一致しない
+var ErrInvalidCalendarToken = errors.New("invalid calendar token")
+
+// icsTimeFormat iCalendar の UTC 日時
+const icsTimeFormat = "20060102T150405Z"
+
+// icsUIDDomain イベントの UID の後ろに付ける値（他のカレンダーの UID と重ならないように）
+const icsUIDDomain = "seeft-slack-notification"
+
+// CalendarService ユーザーのシフトを iCalendar 形式で配信する
+type CalendarService struct {
+	shiftRepo     *repository.ShiftRepository
+	userRepo      *repository.UserRepository
+	tokenRepo     *repository.CalendarTokenRepository
+	actionLogRepo *repository.ActionLogRepository
+	calendar      *ShiftCalendar
+}
+
+func NewCalendarService(
+	shiftRepo *repository.ShiftRepository,
+	userRepo *repository.UserRepository,
+	tokenRepo *repository.CalendarTokenRepository,
+	actionLogRepo *repository.ActionLogRepository,
+	calendar *ShiftCalendar,
+) *CalendarService {
+	return &CalendarService{
+		shiftRepo:     shiftRepo,
+		userRepo:      userRepo,
+		tokenRepo:     tokenRepo,
+		actionLogRepo: actionLogRepo,
+		calendar:      calendar,
+	}
+}
+
+// calendarEvent 連続した同じタスクの枠をまとめた1イベント分
+type calendarEvent struct {
+	first   *model.Shift // 最初の枠のシフト（開始時刻・タスク・天気）
+	endID   int          // 最後の枠の timeID
+	updated time.Time
+}
+
+// calendarDay イベントの SEQUENCE を数える単位（ユーザーの1日分）
+type calendarDay struct {
+	YearID int
+	Date   string
+}
+
+// IssueToken カレンダー購読用のトークンを発行する（以前のトークンは使えなくなる）
+func (s *CalendarService) IssueToken(userID int) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	if err := s.tokenRepo.Upsert(userID, hashCalendarToken(token)); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Feed トークンを確認し、ユーザーのシフトを iCalendar 形式で返す
+// 同じ日付・天気で連続した同じタスクの枠は1つのイベントにまとめ、削除されたシフトは取り消し(CANCELLED)として残す
+func (s *CalendarService) Feed(userID int, token string) ([]byte, error) {
+	hash, err := s.tokenRepo.GetHash(userID)
+	if err != nil {
+		return nil, err
+	}
+	if hash == "" || token == "" || subtle.ConstantTimeCompare([]byte(hash), []byte(hashCalendarToken(token))) != 1 {
+		return nil, ErrInvalidCalendarToken
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.Active() {
+		// 利用停止したユーザーの購読URLは無効にする
+		return nil, ErrInvalidCalendarToken
+	}
+
+	shifts, err := s.shiftRepo.GetAllByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	changeCounts, err := s.actionLogRepo.CountByUserShifts(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.render(user, shifts, changeCounts, time.Now()), nil
+}
+
+// render シフト（年度・日付・天気・時間順、削除済みを含む）を iCalendar にする
+// UID はユーザーと開始枠から作るので、タスクや終わりの時刻が変わっても同じイベントとして更新される
+// SEQUENCE はその日のシフトの変更履歴の件数（変更のたびに増えるので、以前に取り込まれた版を置き換えられる）
+func (s *CalendarService) render(user *model.User, shifts []*model.Shift, changeCounts map[int]int, now time.Time) []byte {
+	var active, deleted []*model.Shift
+	sequences := make(map[calendarDay]int)
+	for _, sh := range shifts {
+		if sh.DeletedAt == nil {
+			active = append(active, sh)
+		} else {
+			deleted = append(deleted, sh)
+		}
+		sequences[calendarDay{YearID: sh.YearID, Date: sh.Date}] += changeCounts[sh.ID]
+	}
+
+	var b strings.Builder
+	writeICSLine(&b, "BEGIN:VCALENDAR")
+	writeICSLine(&b, "VERSION:2.0")
+	writeICSLine(&b, "PRODID:-//seeft-slack-notification//shifts//JA")
+	writeICSLine(&b, "CALSCALE:GREGORIAN")
+	writeICSLine(&b, "METHOD:PUBLISH")
+	writeICSLine(&b, "X-WR-CALNAME:"+escapeICSText("シフト（"+user.Name+"）"))
+	writeICSLine(&b, "X-WR-TIMEZONE:"+s.calendar.Location().String())
+
+	stamp := now.UTC().Format(icsTimeFormat)
+	written := make(map[string]bool)
+	for _, ev := range mergeCalendarEvents(active) {
+		uid := calendarEventUID(user.ID, ev)
+		written[uid] = true
+		s.writeEvent(&b, uid, ev, "CONFIRMED", sequences[calendarDay{YearID: ev.first.YearID, Date: ev.first.Date}], stamp)
+	}
+	for _, ev := range mergeCalendarEvents(deleted) {
+		// 同じ枠から始まる有効なイベントがあれば、そちらで置き換わるので取り消さない
+		uid := calendarEventUID(user.ID, ev)
+		if written[uid] {
+			continue
+		}
+		written[uid] = true
+		s.writeEvent(&b, uid, ev, "CANCELLED", sequences[calendarDay{YearID: ev.first.YearID, Date: ev.first.Date}], stamp)
+	}
+
+	writeICSLine(&b, "END:VCALENDAR")
+	return []byte(b.String())
+}
+
+// calendarEventUID イベントの UID（ユーザー・年度・日付・開始枠）
+// 同じ日付・時間帯の有効なシフトはユーザーごとに1つなので、有効なイベントどうしでは重ならない
+func calendarEventUID(userID int, ev *calendarEvent) string {
+	return fmt.Sprintf("shift-%d-%d-%s-%d@%s", userID, ev.first.YearID, ev.first.Date, ev.first.TimeID, icsUIDDomain)
+}
+
+// mergeCalendarEvents 年度・日付・天気・時間順のシフトを、連続した同じタスクの枠ごとにまとめる
+func mergeCalendarEvents(shifts []*model.Shift) []*calendarEvent {
+	var events []*calendarEvent
+	var last *calendarEvent
+	for _, sh := range shifts {
+		if last != nil &&
+			last.first.YearID == sh.YearID &&
+			last.first.Date == sh.Date &&
+			last.first.Weather == sh.Weather &&
+			last.first.TaskName == sh.TaskName &&
+			last.endID+1 == sh.TimeID {
+			last.endID = sh.TimeID
+			if t := shiftModifiedAt(sh); t.After(last.updated) {
+				last.updated = t
+			}
+			continue
+		}
+
+		last = &calendarEvent{first: sh, endID: sh.TimeID, updated: shiftModifiedAt(sh)}
+		events = append(events, last)
+	}
+	return events
+}
+
+// shiftModifiedAt シフトが最後に変更された日時（削除されていれば削除日時）
+func shiftModifiedAt(sh *model.Shift) time.Time {
+	if sh.DeletedAt != nil {
+		return *sh.DeletedAt
+	}
+	return sh.UpdatedAt
+}
+
+// writeEvent VEVENT を1つ書き出す（日付ラベルが開催日程に無いものは日時が決まらないので出さない）
+// 取り消しは同じ UID・より大きい SEQUENCE で出し、以前に取り込まれたイベントを置き換えさせる
+func (s *CalendarService) writeEvent(b *strings.Builder, uid string, ev *calendarEvent, status string, sequence int, stamp string) {
+	start, ok := s.calendar.StartTime(ev.first.Date, ev.first.TimeID)
+	if !ok {
+		return
+	}
+	end, _ := s.calendar.EndTime(ev.first.Date, ev.endID)
+
+	writeICSLine(b, "BEGIN:VEVENT")
+	writeICSLine(b, "UID:"+escapeICSText(uid))
+	writeICSLine(b, "DTSTAMP:"+stamp)
+	writeICSLine(b, "LAST-MODIFIED:"+ev.updated.UTC().Format(icsTimeFormat))
+	writeICSLine(b, "DTSTART:"+start.UTC().Format(icsTimeFormat))
+	writeICSLine(b, "DTEND:"+end.UTC().Format(icsTimeFormat))
+	writeICSLine(b, fmt.Sprintf("SEQUENCE:%d", sequence))
+	writeICSLine(b, "SUMMARY:"+escapeICSText(fmt.Sprintf("%s（%s）", ev.first.TaskName, ev.first.Weather)))
+	writeICSLine(b, "DESCRIPTION:"+escapeICSText(fmt.Sprintf("%s %s %s〜%s（%s）",
+		ev.first.Date, ev.first.Weather, timeIDToString(ev.first.TimeID), timeIDToString(ev.endID+1), ev.first.TaskName)))
+	writeICSLine(b, "STATUS:"+status)
+	writeICSLine(b, "END:VEVENT")
+}
+
+// icsTextEscaper TEXT 型の値のエスケープ（RFC 5545 3.3.11）
+var icsTextEscaper = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeICSText(s string) string {
+	return icsTextEscaper.Replace(s)
+}
+
+// writeICSLine 1行を CRLF 付きで書き出す。75バイトを超える行は、文字の途中で切らないように折り返す
+func writeICSLine(b *strings.Builder, line string) {
+	const maxLineBytes = 75
+
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > maxLineBytes {
+			b.WriteString("\r\n ")
+			width = 1 // 継続行の先頭の空白
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+}
+
+// hashCalendarToken トークンは DB にハッシュで保存する
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
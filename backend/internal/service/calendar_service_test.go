@@ -0,0 +1,197 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"seeft-slack-notification/internal/model"
+)
+
+func TestCalendarServiceRender(t *testing.T) {
+	s := &CalendarService{calendar: &ShiftCalendar{
+		dates:    map[string]time.Time{"1日目": time.Date(2025, 11, 2, 0, 0, 0, 0, time.FixedZone("JST", 9*60*60))},
+		location: time.FixedZone("JST", 9*60*60),
+	}}
+	user := &model.User{ID: 7, Name: "山田"}
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	// id 分目に最後に更新され、削除されたものは 1時間 id 分目に削除されたシフト
+	base := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	shift := func(id int, weather string, timeID int, task string, deleted bool) *model.Shift {
+		sh := &model.Shift{
+			ID: id, YearID: 1, Date: "1日目", Weather: weather, TimeID: timeID, UserID: 7, TaskName: task,
+			UpdatedAt: base.Add(time.Duration(id) * time.Minute),
+		}
+		if deleted {
+			at := base.Add(time.Hour + time.Duration(id)*time.Minute)
+			sh.DeletedAt = &at
+		}
+		return sh
+	}
+
+	tests := []struct {
+		name         string
+		shifts       []*model.Shift // 年度・日付・天気・時間順
+		changeCounts map[int]int
+		want         string // VEVENT の部分
+	}{
+		{
+			name: "連続した同じタスクの枠を1つにまとめる",
+			shifts: []*model.Shift{
+				shift(1, "晴れ", 33, "救護", false),
+				shift(3, "晴れ", 34, "救護", false),
+				shift(2, "晴れ", 35, "救護", false),
+			},
+			changeCounts: map[int]int{1: 1, 2: 1, 3: 2},
+			want: `BEGIN:VEVENT
+UID:shift-7-1-1日目-33@seeft-slack-notification
+DTSTAMP:20251001T120000Z
+LAST-MODIFIED:20251001T000300Z
+DTSTART:20251102T010000Z
+DTEND:20251102T023000Z
+SEQUENCE:4
+SUMMARY:救護（晴れ）
+DESCRIPTION:1日目 晴れ 10:00〜11:30（救護）
+STATUS:CONFIRMED
+END:VEVENT`,
+		},
+		{
+			name: "途中の枠が削除されたら前後に分け、削除した枠は取り消す",
+			shifts: []*model.Shift{
+				shift(1, "晴れ", 33, "救護", false),
+				shift(2, "晴れ", 34, "救護", true),
+				shift(3, "晴れ", 35, "救護", false),
+			},
+			changeCounts: map[int]int{1: 1, 2: 2, 3: 1},
+			want: `BEGIN:VEVENT
+UID:shift-7-1-1日目-33@seeft-slack-notification
+DTSTAMP:20251001T120000Z
+LAST-MODIFIED:20251001T000100Z
+DTSTART:20251102T010000Z
+DTEND:20251102T013000Z
+SEQUENCE:4
+SUMMARY:救護（晴れ）
+DESCRIPTION:1日目 晴れ 10:00〜10:30（救護）
+STATUS:CONFIRMED
+END:VEVENT
+BEGIN:VEVENT
+UID:shift-7-1-1日目-35@seeft-slack-notification
+DTSTAMP:20251001T120000Z
+LAST-MODIFIED:20251001T000300Z
+DTSTART:20251102T020000Z
+DTEND:20251102T023000Z
+SEQUENCE:4
+SUMMARY:救護（晴れ）
+DESCRIPTION:1日目 晴れ 11:00〜11:30（救護）
+STATUS:CONFIRMED
+END:VEVENT
+BEGIN:VEVENT
+UID:shift-7-1-1日目-34@seeft-slack-notification
+DTSTAMP:20251001T120000Z
+LAST-MODIFIED:20251001T010200Z
+DTSTART:20251102T013000Z
+DTEND:20251102T020000Z
+SEQUENCE:4
+SUMMARY:救護（晴れ）
+DESCRIPTION:1日目 晴れ 10:30〜11:00（救護）
+STATUS:CANCELLED
+END:VEVENT`,
+		},
+		{
+			name: "最初の枠が削除されたら、その枠から始まっていたイベントを取り消す",
+			shifts: []*model.Shift{
+				shift(1, "晴れ", 33, "救護", true),
+				shift(2, "晴れ", 34, "救護", false),
+				shift(3, "晴れ", 35, "救護", false),
+			},
+			changeCounts: map[int]int{1: 2, 2: 1, 3: 1},
+			want: `BEGIN:VEVENT
+UID:shift-7-1-1日目-34@seeft-slack-notification
+DTSTAMP:20251001T120000Z
+LAST-MODIFIED:20251001T000300Z
+DTSTART:20251102T013000Z
+DTEND:20251102T023000Z
+SEQUENCE:4
+SUMMARY:救護（晴れ）
+DESCRIPTION:1日目 晴れ 10:30〜11:30（救護）
+STATUS:CONFIRMED
+END:VEVENT
+BEGIN:VEVENT
+UID:shift-7-1-1日目-33@seeft-slack-notification
+DTSTAMP:20251001T120000Z
+LAST-MODIFIED:20251001T010100Z
+DTSTART:20251102T010000Z
+DTEND:20251102T013000Z
+SEQUENCE:4
+SUMMARY:救護（晴れ）
+DESCRIPTION:1日目 晴れ 10:00〜10:30（救護）
+STATUS:CANCELLED
+END:VEVENT`,
+		},
+		{
+			name: "最初の枠のタスクが変わっても、その枠から始まるイベントの UID は変わらない",
+			shifts: []*model.Shift{
+				shift(1, "晴れ", 33, "受付", false),
+				shift(2, "晴れ", 34, "救護", false),
+			},
+			changeCounts: map[int]int{1: 2, 2: 1},
+			want: `BEGIN:VEVENT
+UID:shift-7-1-1日目-33@seeft-slack-notification
+DTSTAMP:20251001T120000Z
+LAST-MODIFIED:20251001T000100Z
+DTSTART:20251102T010000Z
+DTEND:20251102T013000Z
+SEQUENCE:3
+SUMMARY:受付（晴れ）
+DESCRIPTION:1日目 晴れ 10:00〜10:30（受付）
+STATUS:CONFIRMED
+END:VEVENT
+BEGIN:VEVENT
+UID:shift-7-1-1日目-34@seeft-slack-notification
+DTSTAMP:20251001T120000Z
+LAST-MODIFIED:20251001T000200Z
+DTSTART:20251102T013000Z
+DTEND:20251102T020000Z
+SEQUENCE:3
+SUMMARY:救護（晴れ）
+DESCRIPTION:1日目 晴れ 10:30〜11:00（救護）
+STATUS:CONFIRMED
+END:VEVENT`,
+		},
+		{
+			name: "同じ枠から始まる有効なイベントがあれば、削除したシフトは取り消さない",
+			shifts: []*model.Shift{
+				shift(1, "晴れ", 33, "救護", true),
+				shift(2, "雨", 33, "救護", false),
+			},
+			changeCounts: map[int]int{1: 2, 2: 1},
+			want: `BEGIN:VEVENT
+UID:shift-7-1-1日目-33@seeft-slack-notification
+DTSTAMP:20251001T120000Z
+LAST-MODIFIED:20251001T000200Z
+DTSTART:20251102T010000Z
+DTEND:20251102T013000Z
+SEQUENCE:3
+SUMMARY:救護（雨）
+DESCRIPTION:1日目 雨 10:00〜10:30（救護）
+STATUS:CONFIRMED
+END:VEVENT`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ics := strings.ReplaceAll(string(s.render(user, tt.shifts, tt.changeCounts, now)), "\r\n", "\n")
+
+			begin := strings.Index(ics, "BEGIN:VEVENT")
+			end := strings.LastIndex(ics, "END:VEVENT")
+			if begin < 0 || end < 0 {
+				t.Fatalf("no VEVENT in:\n%s", ics)
+			}
+			if got := ics[begin : end+len("END:VEVENT")]; got != tt.want {
+				t.Errorf("events:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
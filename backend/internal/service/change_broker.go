@@ -0,0 +1,71 @@
+package service
+
+import "sync"
+
+// ChangeBroker 同期で変更があった・既読にしたユーザーの購読者（SSE接続）に「新しい変更や未読件数の変化がある」ことを知らせる
+// 変更の中身は購読者側が action_log から取り直すので、取りこぼしても次の通知や再接続で追いつける
+type ChangeBroker struct {
+	mu          sync.Mutex
+	subscribers map[int]map[chan struct{}]struct{} // ユーザーID -> 購読者
+	done        chan struct{}
+	closed      bool
+}
+
+func NewChangeBroker() *ChangeBroker {
+	return &ChangeBroker{
+		subscribers: make(map[int]map[chan struct{}]struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Subscribe userID の変更を購読する。戻り値の関数で購読を解除する
+func (b *ChangeBroker) Subscribe(userID int) (<-chan struct{}, func()) {
+	// 通知が溜まっても1つにまとまればよいのでバッファは1
+	ch := make(chan struct{}, 1)
+
+	b.mu.Lock()
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[chan struct{}]struct{})
+	}
+	b.subscribers[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers[userID], ch)
+		if len(b.subscribers[userID]) == 0 {
+			delete(b.subscribers, userID)
+		}
+	}
+}
+
+// Notify 各ユーザーの購読者に新しい変更・未読件数の変化があることを知らせる（ブロックしない）
+func (b *ChangeBroker) Notify(userIDs ...int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, userID := range userIDs {
+		for ch := range b.subscribers[userID] {
+			select {
+			case ch <- struct{}{}:
+			default: // 既に未処理の通知があればそれで足りる
+			}
+		}
+	}
+}
+
+// Done シャットダウンが始まったら閉じられる
+func (b *ChangeBroker) Done() <-chan struct{} {
+	return b.done
+}
+
+// Close 購読中の接続を終わらせる（HTTPサーバーの Shutdown より先に呼ぶ）
+func (b *ChangeBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed {
+		b.closed = true
+		close(b.done)
+	}
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"seeft-slack-notification/internal/config"
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/repository"
+)
+
+// maxDigestDeletions 運営チャンネルのまとめに個別に載せる削除の最大件数
+const maxDigestDeletions = 30
+
+// ChannelDigestService 運営チャンネルに、直近の全変更のまとめを毎日投稿する
+type ChannelDigestService struct {
+	actionLogRepo *repository.ActionLogRepository
+	slackService  *SlackService
+	calendar      *ShiftCalendar
+	channelID     string
+	postHour      int
+	postMinute    int
+}
+
+func NewChannelDigestService(
+	cfg *config.Config,
+	actionLogRepo *repository.ActionLogRepository,
+	slackService *SlackService,
+	calendar *ShiftCalendar,
+) *ChannelDigestService {
+	return &ChannelDigestService{
+		actionLogRepo: actionLogRepo,
+		slackService:  slackService,
+		calendar:      calendar,
+		channelID:     cfg.SlackChannelID,
+		postHour:      cfg.ChannelDigestHour,
+		postMinute:    cfg.ChannelDigestMinute,
+	}
+}
+
+// Run ctx が終了するまで、毎日決まった時刻に直近24時間のまとめを投稿する
+func (s *ChannelDigestService) Run(ctx context.Context) {
+	for {
+		next := s.nextPostTime(time.Now())
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		digest, err := s.Build(next.Add(-24*time.Hour), next)
+		if err != nil {
+			log.Printf("Failed to build channel digest: %v", err)
+			continue
+		}
+		if err := s.Post(ctx, digest); err != nil {
+			log.Printf("Failed to post channel digest: %v", err)
+		}
+	}
+}
+
+// nextPostTime now より後の、次の投稿時刻（開催地のタイムゾーン基準）
+func (s *ChannelDigestService) nextPostTime(now time.Time) time.Time {
+	local := now.In(s.calendar.Location())
+	next := time.Date(local.Year(), local.Month(), local.Day(), s.postHour, s.postMinute, 0, 0, local.Location())
+	if !next.After(local) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// Build [from, to) の期間の変更を、日付ラベル・タスク・アクション種別ごとに集計する
+func (s *ChannelDigestService) Build(from, to time.Time) (*model.ChangeDigest, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidInput)
+	}
+
+	entries, err := s.actionLogRepo.GetBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := &model.ChangeDigest{
+		From:      from,
+		To:        to,
+		Total:     len(entries),
+		Groups:    make([]model.ChangeDigestGroup, 0),
+		Deletions: make([]model.ChangeDigestDeletion, 0),
+	}
+
+	counts := make(map[model.ChangeDigestGroup]int)
+	for _, e := range entries {
+		oldTask, newTask := e.TaskChange()
+
+		// UPDATEは変更後のタスク、DELETEは削除されたタスクで数える
+		taskName := newTask
+		if e.ActionType == "DELETE" {
+			taskName = oldTask
+
+			if isStaffedTask(oldTask) {
+				digest.Deletions = append(digest.Deletions, model.ChangeDigestDeletion{
+					UserName:  e.UserName,
+					Date:      e.Date,
+					TimeID:    e.TimeID,
+					Time:      timeIDToString(e.TimeID),
+					Weather:   e.Weather,
+					TaskName:  oldTask,
+					DeletedAt: e.CreatedAt,
+				})
+			}
+		}
+
+		counts[model.ChangeDigestGroup{Date: e.Date, TaskName: taskName, ActionType: e.ActionType}]++
+	}
+
+	for group, count := range counts {
+		group.Count = count
+		digest.Groups = append(digest.Groups, group)
+	}
+	sort.Slice(digest.Groups, func(i, j int) bool {
+		a, b := digest.Groups[i], digest.Groups[j]
+		if c := s.calendar.CompareDates(a.Date, b.Date); c != 0 {
+			return c < 0
+		}
+		if a.TaskName != b.TaskName {
+			return a.TaskName < b.TaskName
+		}
+		return actionOrder(a.ActionType) < actionOrder(b.ActionType)
+	})
+
+	return digest, nil
+}
+
+// Post まとめを運営チャンネルに投稿する
+func (s *ChannelDigestService) Post(ctx context.Context, digest *model.ChangeDigest) error {
+	title := fmt.Sprintf("シフト変更まとめ %s〜%s (%d件)",
+		digest.From.In(s.calendar.Location()).Format("01/02 15:04"),
+		digest.To.In(s.calendar.Location()).Format("01/02 15:04"),
+		digest.Total)
+
+	var lines []string
+	if digest.Total == 0 {
+		lines = append(lines, "この期間の変更はありませんでした。")
+	}
+
+	currentDate := ""
+	for _, g := range digest.Groups {
+		if g.Date != currentDate {
+			currentDate = g.Date
+			lines = append(lines, fmt.Sprintf("*%s*", g.Date))
+		}
+		taskName := g.TaskName
+		if taskName == "" {
+			taskName = "(空欄)"
+		}
+		lines = append(lines, fmt.Sprintf("• %s %s: %d件", taskName, actionTitle(g.ActionType), g.Count))
+	}
+
+	if len(digest.Deletions) > 0 {
+		lines = append(lines, fmt.Sprintf("*:warning: 主な削除 (%d件)*", len(digest.Deletions)))
+		for i, d := range digest.Deletions {
+			if i == maxDigestDeletions {
+				lines = append(lines, fmt.Sprintf("…ほか%d件", len(digest.Deletions)-maxDigestDeletions))
+				break
+			}
+			lines = append(lines, fmt.Sprintf("• %s %s (%s) %s: ~%s~", d.Date, d.Time, d.Weather, d.UserName, d.TaskName))
+		}
+	}
+
+	blocks := buildDigestBlocks(":bar_chart: "+title, lines)
+	if _, err := s.slackService.PostBlocks(ctx, s.channelID, title, blocks); err != nil {
+		return err
+	}
+	return nil
+}
+
+// actionOrder 表示順: 追加 → 変更 → 削除
+func actionOrder(actionType string) int {
+	switch actionType {
+	case "CREATE":
+		return 0
+	case "UPDATE":
+		return 1
+	case "DELETE":
+		return 2
+	default:
+		return 3
+	}
+}
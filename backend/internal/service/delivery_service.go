@@ -0,0 +1,84 @@
+package service
+
+import (
+	"errors"
+	"log"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/repository"
+)
+
+var (
+	errQueueFull    = errors.New("notification queue is full")
+	errShuttingDown = errors.New("notification service is shutting down")
+)
+
+// DeliveryResult 通知1件の送信結果
+type DeliveryResult struct {
+	TS       string // Slackの投稿 ts（メールの場合は空）
+	Attempts int
+	Err      error
+}
+
+// DeliveryService 通知ごとの配信記録（宛先・状態・Slackのts・試行回数）を管理する
+type DeliveryService struct {
+	deliveryRepo *repository.NotificationDeliveryRepository
+}
+
+func NewDeliveryService(deliveryRepo *repository.NotificationDeliveryRepository) *DeliveryService {
+	return &DeliveryService{deliveryRepo: deliveryRepo}
+}
+
+// Record 配信記録を作成する（IDが d.ID にセットされる）
+func (s *DeliveryService) Record(q repository.DBTX, d *model.NotificationDelivery) error {
+	return s.deliveryRepo.Create(q, d)
+}
+
+// Track トランザクション外で配信記録を作成し、送信結果を反映するコールバックを返す
+// 記録に失敗しても通知自体は送る（コールバックはnil）
+func (s *DeliveryService) Track(d *model.NotificationDelivery) func(DeliveryResult) {
+	if err := s.deliveryRepo.Create(nil, d); err != nil {
+		log.Printf("Failed to record %s delivery: %v", d.Kind, err)
+		return nil
+	}
+	if d.Status != model.DeliveryStatusQueued {
+		return nil
+	}
+	return s.ResultHandler(d.ID)
+}
+
+// ResultHandler 送信結果で配信記録を更新するコールバックを返す
+// SlackService / EmailService のワーカーから呼ばれる
+func (s *DeliveryService) ResultHandler(deliveryID int) func(DeliveryResult) {
+	return func(r DeliveryResult) {
+		var err error
+		if r.Err != nil {
+			err = s.deliveryRepo.MarkFailed(deliveryID, r.Attempts, r.Err.Error())
+		} else {
+			err = s.deliveryRepo.MarkSent(deliveryID, r.TS, r.Attempts)
+		}
+		if err != nil {
+			log.Printf("Failed to update delivery %d: %v", deliveryID, err)
+		}
+	}
+}
+
+// MarkSuppressed 別の変更とまとめられたなどで送らなかった記録を suppressed にする
+func (s *DeliveryService) MarkSuppressed(deliveryIDs []int) {
+	if len(deliveryIDs) == 0 {
+		return
+	}
+	if err := s.deliveryRepo.MarkSuppressed(deliveryIDs); err != nil {
+		log.Printf("Failed to mark deliveries suppressed: %v", err)
+	}
+}
+
+// MarkDigestResult まとめ送信待ちだった記録を、まとめ送信の結果で更新する
+func (s *DeliveryService) MarkDigestResult(userID int, actionLogIDs []int, status, slackTS string) error {
+	return s.deliveryRepo.UpdateQueuedForActionLogs(userID, actionLogIDs, status, slackTS)
+}
+
+// List 条件に合う配信記録を取得
+func (s *DeliveryService) List(filter model.DeliveryFilter) ([]*model.NotificationDelivery, error) {
+	return s.deliveryRepo.List(filter)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"log"
+	"strings"
+
+	"seeft-slack-notification/internal/model"
+)
+
+// directNotifier シフト交換・催促・リーダーへの人員変更のまとめなどを、ユーザーの通知設定の送り先(DM・メール)へ1通にまとめて送る
+// 配信記録を残し、送り先が none のユーザーには送らない
+type directNotifier struct {
+	prefService     *PreferenceService
+	slackService    *SlackService
+	emailService    *EmailService
+	deliveryService *DeliveryService
+}
+
+func newDirectNotifier(
+	prefService *PreferenceService,
+	slackService *SlackService,
+	emailService *EmailService,
+	deliveryService *DeliveryService,
+) *directNotifier {
+	return &directNotifier{
+		prefService:     prefService,
+		slackService:    slackService,
+		emailService:    emailService,
+		deliveryService: deliveryService,
+	}
+}
+
+// Send user に title と lines を送る（Slackでは header の先頭に emoji を付ける）
+// syncID は同期で起きた変更の通知なら配信記録に付ける（それ以外は nil）
+// 送れなくても呼び出し元の処理は止めないので、エラーはログにだけ残す
+func (n *directNotifier) Send(syncID *int, user *model.User, kind, emoji, title string, lines []string) {
+	pref, err := n.prefService.Get(user.ID)
+	if err != nil {
+		log.Printf("Failed to load notification preference of user %d: %v", user.ID, err)
+		pref = model.DefaultNotificationPreference(user.ID)
+	}
+
+	delivery := &model.NotificationDelivery{
+		SyncID:    syncID,
+		UserID:    &user.ID,
+		Kind:      kind,
+		Channel:   pref.DeliveryChannel,
+		Recipient: user.SlackUserID,
+		Status:    model.DeliveryStatusQueued,
+	}
+	if pref.DeliveryChannel == model.DeliveryChannelEmail {
+		delivery.Recipient = user.Email
+	}
+	if pref.DeliveryChannel == model.DeliveryChannelNone {
+		delivery.Status = model.DeliveryStatusSuppressed
+		n.deliveryService.Track(delivery)
+		return
+	}
+	onResult := n.deliveryService.Track(delivery)
+
+	switch pref.DeliveryChannel {
+	case model.DeliveryChannelEmail:
+		n.emailService.Enqueue(EmailMessage{
+			To:       user.Email,
+			Subject:  "[シフト通知] " + title,
+			Body:     strings.Join(lines, "\n") + "\n",
+			OnResult: onResult,
+		})
+	default:
+		n.slackService.EnqueueNotification(NotificationPayload{
+			ActionType:  kind,
+			UserName:    user.Name,
+			SlackUserID: user.SlackUserID,
+			Text:        title,
+			Blocks:      buildDigestBlocks(emoji+" "+title, lines),
+			OnResult:    onResult,
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/smtp"
+	"strings"
+	"sync"
+
+	"seeft-slack-notification/internal/config"
+)
+
+// EmailMessage 送信するメール1通分
+type EmailMessage struct {
+	To      string
+	Subject string
+	Body    string
+
+	// 送信結果の受け取り先（配信記録の更新など）。nilなら何もしない
+	OnResult func(DeliveryResult)
+}
+
+// report 送信結果を OnResult に渡す
+func (m EmailMessage) report(r DeliveryResult) {
+	if m.OnResult != nil {
+		m.OnResult(r)
+	}
+}
+
+// EmailService 通知メールを非同期で送信する（SlackServiceのメール版）
+type EmailService struct {
+	host     string
+	port     string
+	user     string
+	password string
+	from     string
+
+	queue  chan EmailMessage
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewEmailService(cfg *config.Config) *EmailService {
+	s := &EmailService{
+		host:     cfg.SMTPHost,
+		port:     cfg.SMTPPort,
+		user:     cfg.SMTPUser,
+		password: cfg.SMTPPassword,
+		from:     cfg.SMTPFrom,
+		queue:    make(chan EmailMessage, QueueSize),
+	}
+
+	s.wg.Add(1)
+	go s.runWorker()
+
+	return s
+}
+
+// Enabled SMTPが設定されているか
+func (s *EmailService) Enabled() bool {
+	return s.host != ""
+}
+
+// EnqueueNotification シフト変更通知をメールとしてキューに追加する
+func (s *EmailService) EnqueueNotification(to string, p NotificationPayload) {
+	s.Enqueue(EmailMessage{
+		To:       to,
+		Subject:  fmt.Sprintf("[シフト通知] %s %s %s", actionTitle(p.ActionType), p.Date, timeIDToString(p.TimeID)),
+		Body:     buildPlainText(p),
+		OnResult: p.OnResult,
+	})
+}
+
+// Enqueue メールをキューに追加する（呼び出し元は待たされない）
+func (s *EmailService) Enqueue(msg EmailMessage) {
+	if msg.To == "" {
+		log.Printf("Warning: email address is not registered, skipping email: %s", msg.Subject)
+		msg.report(DeliveryResult{Err: errors.New("email address is not registered")})
+		return
+	}
+	if !s.Enabled() {
+		log.Printf("Warning: SMTP is not configured, dropping email to %s", msg.To)
+		msg.report(DeliveryResult{Err: errors.New("smtp is not configured")})
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		log.Printf("Error: email service is shutting down, dropping email to %s", msg.To)
+		msg.report(DeliveryResult{Err: errShuttingDown})
+		return
+	}
+
+	select {
+	case s.queue <- msg:
+	default:
+		log.Println("Error: email queue is full, dropping message")
+		msg.report(DeliveryResult{Err: errQueueFull})
+	}
+}
+
+// Shutdown 新規の受付を止め、残りのメールを ctx の期限まで送り切る
+func (s *EmailService) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("email queue drain timed out: %w", ctx.Err())
+	}
+}
+
+// runWorker キューから取り出して送信する（裏方）
+func (s *EmailService) runWorker() {
+	defer s.wg.Done()
+
+	for msg := range s.queue {
+		err := s.send(msg)
+		if err != nil {
+			log.Printf("Failed to send email to %s: %v", msg.To, err)
+		}
+		msg.report(DeliveryResult{Attempts: 1, Err: err})
+	}
+}
+
+// send SMTPで1通送信する
+func (s *EmailService) send(msg EmailMessage) error {
+	var auth smtp.Auth
+	if s.user != "" {
+		auth = smtp.PlainAuth("", s.user, s.password, s.host)
+	}
+
+	header := []string{
+		"From: " + s.from,
+		"To: " + msg.To,
+		"Subject: " + msg.Subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+	}
+	body := strings.Join(header, "\r\n") + "\r\n\r\n" + msg.Body
+
+	addr := fmt.Sprintf("%s:%s", s.host, s.port)
+	if err := smtp.SendMail(addr, auth, s.from, []string{msg.To}, []byte(body)); err != nil {
+		return fmt.Errorf("smtp send error: %w", err)
+	}
+	return nil
+}
+
+// buildPlainText メール本文（Slackのブロックと同じ内容をテキストで）
+func buildPlainText(p NotificationPayload) string {
+	lines := []string{
+		fmt.Sprintf("%s通知", actionTitle(p.ActionType)),
+		"",
+		fmt.Sprintf("ユーザー: %s", p.UserName),
+		fmt.Sprintf("日付: %s", p.Date),
+		fmt.Sprintf("時刻: %s", timeIDToString(p.TimeID)),
+		fmt.Sprintf("天気: %s", p.weatherText("%s → %s")),
+	}
+
+	switch {
+	case p.ActionType == "UPDATE" && p.OldTaskName == p.TaskName:
+		lines = append(lines, fmt.Sprintf("タスク: %s", p.TaskName))
+	case p.ActionType == "UPDATE":
+		lines = append(lines, fmt.Sprintf("変更前: %s", p.OldTaskName), fmt.Sprintf("変更後: %s", p.TaskName))
+	case p.ActionType == "CREATE":
+		lines = append(lines, fmt.Sprintf("タスク: %s", p.TaskName))
+	case p.ActionType == "DELETE":
+		lines = append(lines, fmt.Sprintf("削除されたタスク: %s", p.OldTaskName))
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
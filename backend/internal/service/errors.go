@@ -0,0 +1,18 @@
+package service
+
+import (
+	"errors"
+
+	"seeft-slack-notification/internal/repository"
+)
+
+// エラーの種類（ハンドラはこれらで HTTP ステータスを決める）
+// 種類の付いたエラーのメッセージは、そのまま利用者に返してよい内容にする
+var (
+	// ErrInvalidInput 入力値が不正
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrNotFound 対象が存在しない
+	ErrNotFound = repository.ErrNotFound
+	// ErrConflict 既存のデータと矛盾する
+	ErrConflict = repository.ErrConflict
+)
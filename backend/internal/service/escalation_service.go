@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"seeft-slack-notification/internal/config"
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/repository"
+)
+
+// EscalationService 開催直前の変更が既読にならないとき、本人に再送し、それでも未読ならタスクリーダーに知らせる
+// 対象は開始まで window 以内のシフトへの変更で、シフトごとに最新の変更だけを見る
+type EscalationService struct {
+	escalationRepo *repository.EscalationRepository
+	userRepo       *repository.UserRepository
+	leadService    *TaskLeadService
+	notifier       *directNotifier
+	calendar       *ShiftCalendar
+
+	window      time.Duration // 開始までこの時間以内のシフトへの変更が対象
+	remindAfter time.Duration // 変更からこの時間経っても未読なら本人に再送
+	leadAfter   time.Duration // 変更からこの時間経っても未読ならリーダーに通知
+	interval    time.Duration // 確認する間隔
+}
+
+func NewEscalationService(
+	cfg *config.Config,
+	escalationRepo *repository.EscalationRepository,
+	userRepo *repository.UserRepository,
+	leadService *TaskLeadService,
+	prefService *PreferenceService,
+	slackService *SlackService,
+	emailService *EmailService,
+	deliveryService *DeliveryService,
+	calendar *ShiftCalendar,
+) *EscalationService {
+	return &EscalationService{
+		escalationRepo: escalationRepo,
+		userRepo:       userRepo,
+		leadService:    leadService,
+		notifier:       newDirectNotifier(prefService, slackService, emailService, deliveryService),
+		calendar:       calendar,
+		window:         cfg.UnackWindow,
+		remindAfter:    cfg.UnackRemindAfter,
+		leadAfter:      cfg.UnackLeadAfter,
+		interval:       cfg.UnackCheckInterval,
+	}
+}
+
+// Run ctx が終了するまで interval ごとに未読の変更を確認する
+func (s *EscalationService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Escalate(ctx, time.Now()); err != nil {
+				log.Printf("Failed to escalate unacknowledged changes: %v", err)
+			}
+		}
+	}
+}
+
+// Outstanding 未読のままの開催直前の変更を、シフトの開始が近い順に返す（まだ始まっていないシフトのみ）
+func (s *EscalationService) Outstanding(filter model.UnacknowledgedFilter, now time.Time) ([]*model.UnacknowledgedChange, error) {
+	// 開始が now より後で、変更から開始まで window 以内なら、変更は now - window より後に起きている
+	changes, err := s.escalationRepo.GetUnacknowledgedSince(now.Add(-s.window))
+	if err != nil {
+		return nil, err
+	}
+
+	outstanding := make([]*model.UnacknowledgedChange, 0, len(changes))
+	for _, c := range changes {
+		if filter.UserID != 0 && c.UserID != filter.UserID {
+			continue
+		}
+		if filter.TaskName != "" && c.OldTaskName != filter.TaskName && c.NewTaskName != filter.TaskName {
+			continue
+		}
+
+		start, ok := s.calendar.StartTime(c.Date, c.TimeID)
+		if !ok || !start.After(now) || start.Sub(c.ChangedAt) > s.window {
+			continue
+		}
+		c.StartsAt = start
+		outstanding = append(outstanding, c)
+	}
+
+	sort.SliceStable(outstanding, func(i, j int) bool {
+		a, b := outstanding[i], outstanding[j]
+		if !a.StartsAt.Equal(b.StartsAt) {
+			return a.StartsAt.Before(b.StartsAt)
+		}
+		if a.UserName != b.UserName {
+			return a.UserName < b.UserName
+		}
+		return a.ShiftID < b.ShiftID
+	})
+	return outstanding, nil
+}
+
+// Escalate 未読のままの変更に、経過時間に応じた催促を送る
+// 本人への再送・リーダーへの通知は、それぞれ1人（1リーダー）につき1通にまとめる
+func (s *EscalationService) Escalate(ctx context.Context, now time.Time) error {
+	changes, err := s.Outstanding(model.UnacknowledgedFilter{}, now)
+	if err != nil {
+		return err
+	}
+
+	reminders := make(map[int][]*model.UnacknowledgedChange) // ユーザーID -> 再送する変更
+	var toLeads []*model.UnacknowledgedChange
+	for _, c := range changes {
+		age := now.Sub(c.ChangedAt)
+		switch {
+		case c.ReminderSentAt == nil && age >= s.remindAfter:
+			reminders[c.UserID] = append(reminders[c.UserID], c)
+		case c.ReminderSentAt != nil && c.LeadNotifiedAt == nil && age >= s.leadAfter:
+			toLeads = append(toLeads, c)
+		}
+	}
+
+	for userID, cs := range reminders {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.sendReminder(userID, cs)
+	}
+
+	if len(toLeads) > 0 && ctx.Err() == nil {
+		s.notifyLeads(toLeads)
+	}
+	return nil
+}
+
+// sendReminder 本人に未読の変更をまとめて再送し、再送したことを記録する
+func (s *EscalationService) sendReminder(userID int, changes []*model.UnacknowledgedChange) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		log.Printf("Failed to load user %d for reminder: %v", userID, err)
+		return
+	}
+
+	lines := make([]string, 0, len(changes)+1)
+	for _, c := range changes {
+		lines = append(lines, formatUnacknowledgedLine(c, false))
+	}
+	lines = append(lines, "アプリで確認して既読にしてください")
+
+	title := fmt.Sprintf("まだ確認されていない直前のシフト変更があります (%d件)", len(changes))
+	s.notifier.Send(nil, user, "UNACK_REMINDER", ":bell:", title, lines)
+
+	for _, c := range changes {
+		if err := s.escalationRepo.Record(c.ActionLogID, model.EscalationStageReminder); err != nil {
+			log.Printf("Failed to record reminder for action log %d: %v", c.ActionLogID, err)
+		}
+	}
+}
+
+// notifyLeads 再送しても未読の変更を、変わったタスクのリーダーにまとめて知らせ、知らせたことを記録する
+func (s *EscalationService) notifyLeads(changes []*model.UnacknowledgedChange) {
+	leadsByTask, err := s.leadService.LeadsByTask()
+	if err != nil {
+		log.Printf("Failed to load task leads: %v", err)
+		return
+	}
+
+	// リーダー -> 担当タスクの未読の変更（変更前後のタスクのどちらかを担当していれば対象）
+	byLead := make(map[int][]*model.UnacknowledgedChange)
+	for _, c := range changes {
+		seen := make(map[int]bool)
+		for _, task := range []string{c.OldTaskName, c.NewTaskName} {
+			if !isStaffedTask(task) {
+				continue
+			}
+			for _, leadID := range leadsByTask[task] {
+				if !seen[leadID] {
+					seen[leadID] = true
+					byLead[leadID] = append(byLead[leadID], c)
+				}
+			}
+		}
+	}
+
+	for leadID, cs := range byLead {
+		lead, err := s.userRepo.GetByID(leadID)
+		if err != nil {
+			log.Printf("Failed to load lead %d: %v", leadID, err)
+			continue
+		}
+
+		lines := make([]string, 0, len(cs))
+		for _, c := range cs {
+			lines = append(lines, formatUnacknowledgedLine(c, true))
+		}
+		title := fmt.Sprintf("担当タスクの直前の変更が確認されていません (%d件)", len(cs))
+		s.notifier.Send(nil, lead, "UNACK_LEAD", ":warning:", title, lines)
+	}
+
+	// リーダーがいないタスクの変更も、同じ変更で何度も確認しないよう記録する
+	for _, c := range changes {
+		if err := s.escalationRepo.Record(c.ActionLogID, model.EscalationStageLead); err != nil {
+			log.Printf("Failed to record lead escalation for action log %d: %v", c.ActionLogID, err)
+		}
+	}
+}
+
+// formatUnacknowledgedLine 1件分の行 "• 1日目 10:00 (晴れ) 受付 → 救護"（withUser なら先頭に名前）
+func formatUnacknowledgedLine(c *model.UnacknowledgedChange, withUser bool) string {
+	var change string
+	switch c.ActionType {
+	case "CREATE":
+		change = c.NewTaskName + " (追加)"
+	case "DELETE":
+		change = c.OldTaskName + " (削除)"
+	default:
+		change = c.OldTaskName + " → " + c.NewTaskName
+	}
+
+	line := fmt.Sprintf("• %s %s (%s) %s", c.Date, timeIDToString(c.TimeID), c.Weather, change)
+	if withUser {
+		line = fmt.Sprintf("• %s: %s %s (%s) %s", c.UserName, c.Date, timeIDToString(c.TimeID), c.Weather, change)
+	}
+	return line
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"log"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/repository"
+)
+
+// HistoryService シフトの変更履歴を action_log から組み立てる
+type HistoryService struct {
+	shiftRepo     *repository.ShiftRepository
+	actionLogRepo *repository.ActionLogRepository
+}
+
+func NewHistoryService(shiftRepo *repository.ShiftRepository, actionLogRepo *repository.ActionLogRepository) *HistoryService {
+	return &HistoryService{
+		shiftRepo:     shiftRepo,
+		actionLogRepo: actionLogRepo,
+	}
+}
+
+// ShiftHistory シフトの変更履歴を古い順に返す（削除済みのシフトも対象）
+// 見られるのはシフトの本人と、admin・そのタスクの lead（問い合わせの確認用）
+func (s *HistoryService) ShiftHistory(actor *model.Principal, shiftID int) (*model.ShiftHistory, error) {
+	shift, err := s.shiftRepo.GetByID(shiftID)
+	if err != nil {
+		return nil, err
+	}
+	if shift == nil {
+		return nil, ErrShiftNotFound
+	}
+	if shift.UserID != actor.UserID && !actor.CanManageTask(shift.TaskName) {
+		return nil, ErrNotShiftOwner
+	}
+
+	logs, err := s.actionLogRepo.GetByShiftID(shiftID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]model.ShiftHistoryEntry, 0, len(logs))
+	for _, l := range logs {
+		diff, err := l.Diff()
+		if err != nil {
+			// 壊れた履歴があっても残りは返す
+			log.Printf("Failed to parse diff payload of action_log %d: %v", l.ID, err)
+		}
+		entries = append(entries, model.ShiftHistoryEntry{
+			ID:         l.ID,
+			SyncID:     l.SyncID,
+			ActionType: l.ActionType,
+			Diff:       diff,
+			CreatedAt:  l.CreatedAt,
+		})
+	}
+
+	return &model.ShiftHistory{
+		Shift:   shift,
+		Entries: entries,
+	}, nil
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"seeft-slack-notification/internal/model"
+)
+
+// pendingNotification コミット後に送る通知
+// トランザクションが確定する前に送ってしまわないよう、同期中はここに溜めておく
+type pendingNotification struct {
+	userID     int
+	slot       SlotKey
+	deliveryID int
+	route      string // "dm" or "email"
+	email      string
+	payload    NotificationPayload
+}
+
+// debounceKeyOf 保留をまとめる単位（ユーザーごとのシフト）
+// 削除→再作成でシフトIDが変わっても、天気が変わっても同じシフトとして扱う
+func debounceKeyOf(n *pendingNotification) ShiftKey {
+	return ShiftKey{UserID: n.userID, YearID: n.slot.YearID, Date: n.slot.Date, TimeID: n.slot.TimeID}
+}
+
+// heldNotification 保留中の通知（同じ枠への変更をまとめたもの）
+type heldNotification struct {
+	before        string // 保留を始めた時点の変更前タスク（未割り当てなら空）
+	beforeWeather string // 保留を始めた時点の変更前の天気（未割り当てなら空）
+	last          *pendingNotification
+	deliveryIDs   []int // まとめられて送らなかった変更の配信記録
+	timer         *time.Timer
+}
+
+// NotificationDebouncer 即時通知を枠ごとに一定時間保留し、その間の変更を1通にまとめる
+// A→B→A のように元に戻った場合は何も送らない（変更履歴は action_log に全て残る）
+type NotificationDebouncer struct {
+	window          time.Duration
+	slackService    *SlackService
+	emailService    *EmailService
+	deliveryService *DeliveryService
+
+	// send / suppress 送信と、送らなかった配信記録の更新（テストで差し替える）
+	send     func(n *pendingNotification)
+	suppress func(deliveryIDs []int)
+
+	mu     sync.Mutex
+	held   map[ShiftKey]*heldNotification
+	closed bool
+}
+
+func NewNotificationDebouncer(
+	window time.Duration,
+	slackService *SlackService,
+	emailService *EmailService,
+	deliveryService *DeliveryService,
+) *NotificationDebouncer {
+	d := &NotificationDebouncer{
+		window:          window,
+		slackService:    slackService,
+		emailService:    emailService,
+		deliveryService: deliveryService,
+		held:            make(map[ShiftKey]*heldNotification),
+	}
+	d.send = d.dispatch
+	d.suppress = deliveryService.MarkSuppressed
+	return d
+}
+
+// Hold 通知を保留する。保留中の枠に新しい変更が来たら、保留時間を延長してまとめる
+func (d *NotificationDebouncer) Hold(n *pendingNotification) {
+	d.mu.Lock()
+	if d.window <= 0 || d.closed {
+		d.mu.Unlock()
+		d.send(n)
+		return
+	}
+
+	key := debounceKeyOf(n)
+	h, ok := d.held[key]
+	if !ok {
+		h = &heldNotification{before: n.payload.OldTaskName, beforeWeather: n.payload.OldWeather}
+		h.timer = time.AfterFunc(d.window, func() { d.flush(key, h) })
+		d.held[key] = h
+	} else {
+		h.deliveryIDs = append(h.deliveryIDs, h.last.deliveryID)
+		h.timer.Reset(d.window)
+	}
+	h.last = n
+	d.mu.Unlock()
+}
+
+// Flush 保留中の通知を全て今すぐ送る（シャットダウン時に SlackService より先に呼ぶ）
+// 以降の Hold は保留せずにそのまま送る
+func (d *NotificationDebouncer) Flush() {
+	d.mu.Lock()
+	d.closed = true
+	held := d.held
+	d.held = make(map[ShiftKey]*heldNotification)
+	d.mu.Unlock()
+
+	if len(held) > 0 {
+		log.Printf("Flushing %d held notifications", len(held))
+	}
+
+	for _, h := range held {
+		h.timer.Stop()
+		d.deliver(h)
+	}
+}
+
+// flush 保留時間が過ぎた枠の通知を送る
+func (d *NotificationDebouncer) flush(key ShiftKey, h *heldNotification) {
+	d.mu.Lock()
+	// 延長や Flush で既に送られていれば何もしない
+	if d.held[key] != h {
+		d.mu.Unlock()
+		return
+	}
+	delete(d.held, key)
+	d.mu.Unlock()
+
+	d.deliver(h)
+}
+
+// deliver まとめた結果（最初の変更前 → 最後の変更後）を送る
+func (d *NotificationDebouncer) deliver(h *heldNotification) {
+	n, ok := h.coalesce()
+	if !ok {
+		// 元に戻っただけなので送らない
+		d.suppress(append(h.deliveryIDs, h.last.deliveryID))
+		return
+	}
+
+	d.suppress(h.deliveryIDs)
+	d.send(n)
+}
+
+// coalesce まとめた結果の通知（最後の変更の通知を、最初の変更前からの差分に書き換える）
+// タスクも天気も保留前と同じ（追加→削除を含む）なら ok が false
+func (h *heldNotification) coalesce() (*pendingNotification, bool) {
+	n := h.last
+	after := n.payload.TaskName
+
+	if h.before == after && (after == "" || h.beforeWeather == n.payload.Weather) {
+		return nil, false
+	}
+
+	n.payload.OldTaskName = h.before
+	n.payload.OldWeather = h.beforeWeather
+	switch {
+	case h.before == "":
+		n.payload.ActionType = "CREATE"
+	case after == "":
+		n.payload.ActionType = "DELETE"
+	default:
+		n.payload.ActionType = "UPDATE"
+	}
+	return n, true
+}
+
+// dispatch 通知を経路ごとのキューに放り込む (非同期)
+func (d *NotificationDebouncer) dispatch(n *pendingNotification) {
+	switch n.route {
+	case model.DeliveryChannelEmail:
+		d.emailService.EnqueueNotification(n.email, n.payload)
+	default:
+		d.slackService.EnqueueNotification(n.payload)
+	}
+}
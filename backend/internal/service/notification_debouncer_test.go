@@ -0,0 +1,207 @@
+package service
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubNotifier 送られた通知と suppressed にされた配信記録を記録する
+type stubNotifier struct {
+	mu         sync.Mutex
+	sent       []NotificationPayload
+	suppressed []int
+	sentCh     chan struct{}
+}
+
+func newStubbedDebouncer(window time.Duration) (*NotificationDebouncer, *stubNotifier) {
+	stub := &stubNotifier{sentCh: make(chan struct{}, 16)}
+	d := NewNotificationDebouncer(window, nil, nil, nil)
+	d.send = func(n *pendingNotification) {
+		stub.mu.Lock()
+		stub.sent = append(stub.sent, n.payload)
+		stub.mu.Unlock()
+		stub.sentCh <- struct{}{}
+	}
+	d.suppress = func(deliveryIDs []int) {
+		stub.mu.Lock()
+		stub.suppressed = append(stub.suppressed, deliveryIDs...)
+		stub.mu.Unlock()
+	}
+	return d, stub
+}
+
+// shiftChange 佐藤の 1日目 10:00 の枠への即時通知
+func shiftChange(deliveryID int, action, oldTask, oldWeather, task, weather string) *pendingNotification {
+	return &pendingNotification{
+		userID:     1,
+		slot:       SlotKey{YearID: 1, Date: "1日目", Weather: weather, TimeID: 33},
+		deliveryID: deliveryID,
+		payload: NotificationPayload{
+			ActionType:  action,
+			UserName:    "佐藤",
+			Date:        "1日目",
+			TimeID:      33,
+			Weather:     weather,
+			TaskName:    task,
+			OldTaskName: oldTask,
+			OldWeather:  oldWeather,
+		},
+	}
+}
+
+func TestNotificationDebouncerFlush(t *testing.T) {
+	tests := []struct {
+		name           string
+		changes        []*pendingNotification
+		wantSent       []NotificationPayload
+		wantSuppressed []int
+	}{
+		{
+			name: "天気だけの変更はそのまま送る",
+			changes: []*pendingNotification{
+				shiftChange(1, "UPDATE", "救護", "晴れ", "救護", "雨"),
+			},
+			wantSent: []NotificationPayload{
+				{ActionType: "UPDATE", UserName: "佐藤", Date: "1日目", TimeID: 33, Weather: "雨", TaskName: "救護", OldTaskName: "救護", OldWeather: "晴れ"},
+			},
+		},
+		{
+			name: "A→B→A は送らず、どちらの配信記録も suppressed",
+			changes: []*pendingNotification{
+				shiftChange(1, "UPDATE", "救護", "晴れ", "受付", "晴れ"),
+				shiftChange(2, "UPDATE", "受付", "晴れ", "救護", "晴れ"),
+			},
+			wantSuppressed: []int{1, 2},
+		},
+		{
+			name: "A→B→A でも天気が変わっていれば送る",
+			changes: []*pendingNotification{
+				shiftChange(1, "UPDATE", "救護", "晴れ", "受付", "晴れ"),
+				shiftChange(2, "UPDATE", "受付", "晴れ", "救護", "雨"),
+			},
+			wantSent: []NotificationPayload{
+				{ActionType: "UPDATE", UserName: "佐藤", Date: "1日目", TimeID: 33, Weather: "雨", TaskName: "救護", OldTaskName: "救護", OldWeather: "晴れ"},
+			},
+			wantSuppressed: []int{1},
+		},
+		{
+			name: "天気が変わって元に戻ったら送らない",
+			changes: []*pendingNotification{
+				shiftChange(1, "UPDATE", "救護", "晴れ", "救護", "雨"),
+				shiftChange(2, "UPDATE", "救護", "雨", "救護", "晴れ"),
+			},
+			wantSuppressed: []int{1, 2},
+		},
+		{
+			name: "A→B→C は A→C として最後の配信記録で送る",
+			changes: []*pendingNotification{
+				shiftChange(1, "UPDATE", "救護", "晴れ", "受付", "晴れ"),
+				shiftChange(2, "UPDATE", "受付", "晴れ", "警備", "晴れ"),
+				shiftChange(3, "UPDATE", "警備", "晴れ", "本部", "晴れ"),
+			},
+			wantSent: []NotificationPayload{
+				{ActionType: "UPDATE", UserName: "佐藤", Date: "1日目", TimeID: 33, Weather: "晴れ", TaskName: "本部", OldTaskName: "救護", OldWeather: "晴れ"},
+			},
+			wantSuppressed: []int{1, 2},
+		},
+		{
+			name: "追加→削除は送らない",
+			changes: []*pendingNotification{
+				shiftChange(1, "CREATE", "", "", "救護", "晴れ"),
+				shiftChange(2, "DELETE", "救護", "晴れ", "", "晴れ"),
+			},
+			wantSuppressed: []int{1, 2},
+		},
+		{
+			name: "追加→変更は変更後のタスクの追加",
+			changes: []*pendingNotification{
+				shiftChange(1, "CREATE", "", "", "救護", "晴れ"),
+				shiftChange(2, "UPDATE", "救護", "晴れ", "受付", "雨"),
+			},
+			wantSent: []NotificationPayload{
+				{ActionType: "CREATE", UserName: "佐藤", Date: "1日目", TimeID: 33, Weather: "雨", TaskName: "受付"},
+			},
+			wantSuppressed: []int{1},
+		},
+		{
+			name: "変更→削除は元のタスクの削除",
+			changes: []*pendingNotification{
+				shiftChange(1, "UPDATE", "救護", "晴れ", "受付", "晴れ"),
+				shiftChange(2, "DELETE", "受付", "晴れ", "", "晴れ"),
+			},
+			wantSent: []NotificationPayload{
+				{ActionType: "DELETE", UserName: "佐藤", Date: "1日目", TimeID: 33, Weather: "晴れ", OldTaskName: "救護", OldWeather: "晴れ"},
+			},
+			wantSuppressed: []int{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, stub := newStubbedDebouncer(time.Hour)
+			for _, n := range tt.changes {
+				d.Hold(n)
+			}
+			if len(stub.sent) != 0 {
+				t.Fatalf("sent %d notifications before Flush", len(stub.sent))
+			}
+
+			d.Flush()
+
+			if !reflect.DeepEqual(stub.sent, tt.wantSent) {
+				t.Errorf("sent = %+v, want %+v", stub.sent, tt.wantSent)
+			}
+			if !reflect.DeepEqual(stub.suppressed, tt.wantSuppressed) {
+				t.Errorf("suppressed = %v, want %v", stub.suppressed, tt.wantSuppressed)
+			}
+		})
+	}
+}
+
+func TestNotificationDebouncerSendsAfterWindow(t *testing.T) {
+	d, stub := newStubbedDebouncer(10 * time.Millisecond)
+	d.Hold(shiftChange(1, "UPDATE", "救護", "晴れ", "受付", "晴れ"))
+	d.Hold(shiftChange(2, "UPDATE", "受付", "晴れ", "警備", "晴れ"))
+
+	select {
+	case <-stub.sentCh:
+	case <-time.After(time.Second):
+		t.Fatal("held notification was not sent after the window")
+	}
+
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	want := []NotificationPayload{
+		{ActionType: "UPDATE", UserName: "佐藤", Date: "1日目", TimeID: 33, Weather: "晴れ", TaskName: "警備", OldTaskName: "救護", OldWeather: "晴れ"},
+	}
+	if !reflect.DeepEqual(stub.sent, want) {
+		t.Errorf("sent = %+v, want %+v", stub.sent, want)
+	}
+	if !reflect.DeepEqual(stub.suppressed, []int{1}) {
+		t.Errorf("suppressed = %v, want [1]", stub.suppressed)
+	}
+}
+
+func TestNotificationDebouncerSendsImmediately(t *testing.T) {
+	t.Run("保留時間が0なら保留しない", func(t *testing.T) {
+		d, stub := newStubbedDebouncer(0)
+		d.Hold(shiftChange(1, "UPDATE", "救護", "晴れ", "受付", "晴れ"))
+		d.Hold(shiftChange(2, "UPDATE", "受付", "晴れ", "救護", "晴れ"))
+
+		if len(stub.sent) != 2 {
+			t.Errorf("sent %d notifications, want 2", len(stub.sent))
+		}
+	})
+
+	t.Run("Flush の後は保留しない", func(t *testing.T) {
+		d, stub := newStubbedDebouncer(time.Hour)
+		d.Flush()
+		d.Hold(shiftChange(1, "UPDATE", "救護", "晴れ", "受付", "晴れ"))
+
+		if len(stub.sent) != 1 {
+			t.Errorf("sent %d notifications, want 1", len(stub.sent))
+		}
+	})
+}
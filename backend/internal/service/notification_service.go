@@ -0,0 +1,136 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/repository"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// NotificationService アプリ向けの通知一覧・タイムラインを変更履歴から組み立てる
+type NotificationService struct {
+	actionLogRepo *repository.ActionLogRepository
+}
+
+func NewNotificationService(actionLogRepo *repository.ActionLogRepository) *NotificationService {
+	return &NotificationService{
+		actionLogRepo: actionLogRepo,
+	}
+}
+
+// List ユーザーの通知一覧（新しい順、最大 limit 件）
+func (s *NotificationService) List(userID int, unreadOnly bool, limit int) ([]model.NotificationResponse, error) {
+	entries, err := s.actionLogRepo.GetTimeline(model.TimelineFilter{
+		UserID:     userID,
+		UnreadOnly: unreadOnly,
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toNotificationResponses(entries), nil
+}
+
+// Timeline ユーザーの全シフトの変更を新しい順に1ページ分返す
+// cursor は前のページの next_cursor（空なら先頭ページ）
+func (s *NotificationService) Timeline(filter model.TimelineFilter, cursor string) (*model.TimelinePage, error) {
+	if cursor != "" {
+		before, err := decodeTimelineCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.Before = before
+	}
+
+	// 次のページがあるか判定するため1件多く取る
+	limit := filter.Limit
+	filter.Limit = limit + 1
+
+	entries, err := s.actionLogRepo.GetTimeline(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.TimelinePage{}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		last := entries[len(entries)-1]
+		next := encodeTimelineCursor(model.TimelineCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+		page.NextCursor = &next
+	}
+	page.Entries = toNotificationResponses(entries)
+	return page, nil
+}
+
+// Since afterID より後の変更を古い順に最大 limit 件返す（SSEでの配信・再開用）
+func (s *NotificationService) Since(userID, afterID, limit int) ([]model.NotificationResponse, error) {
+	entries, err := s.actionLogRepo.GetTimeline(model.TimelineFilter{
+		UserID:    userID,
+		AfterID:   afterID,
+		Ascending: true,
+		Limit:     limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toNotificationResponses(entries), nil
+}
+
+// LatestID ユーザーの最新の変更のID（変更が無ければ0）
+func (s *NotificationService) LatestID(userID int) (int, error) {
+	entries, err := s.actionLogRepo.GetTimeline(model.TimelineFilter{UserID: userID, Limit: 1})
+	if err != nil || len(entries) == 0 {
+		return 0, err
+	}
+	return entries[0].ID, nil
+}
+
+// toNotificationResponses Flutterの ShiftNotification と同じ形に詰め替える
+func toNotificationResponses(entries []*model.NotificationEntry) []model.NotificationResponse {
+	notifications := make([]model.NotificationResponse, 0, len(entries))
+	for _, e := range entries {
+		oldTask, newTask := e.TaskChange()
+		notifications = append(notifications, model.NotificationResponse{
+			ID:          e.ID,
+			ShiftID:     e.ShiftID,
+			ActionType:  e.ActionType,
+			UserName:    e.UserName,
+			YearID:      e.YearID,
+			TimeID:      e.TimeID,
+			Date:        e.Date,
+			Weather:     e.Weather,
+			OldTaskName: oldTask,
+			NewTaskName: newTask,
+			IsRead:      e.IsRead,
+			// created_at はUTCで保存されている
+			CreatedAt: e.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	return notifications
+}
+
+// encodeTimelineCursor "作成日時(UnixMicro):ID" をURLで使えるbase64にする
+func encodeTimelineCursor(c model.TimelineCursor) string {
+	raw := fmt.Sprintf("%d:%d", c.CreatedAt.UnixMicro(), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeTimelineCursor(s string) (*model.TimelineCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var micros int64
+	var id int
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &micros, &id); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &model.TimelineCursor{CreatedAt: time.UnixMicro(micros).UTC(), ID: id}, nil
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"seeft-slack-notification/internal/config"
+	"seeft-slack-notification/internal/model"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OIDCProvider OpenID Connect (Sign in with Slack) のクライアント
+// エンドポイントは issuer の /.well-known/openid-configuration から取得するので、
+// 開発時は cmd/fakeslack のスタブにも同じコードで繋がる
+type OIDCProvider struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	httpClient   *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]*rsa.PublicKey // kid -> 公開鍵
+}
+
+// oidcDiscovery /.well-known/openid-configuration のうち使う項目
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// idTokenClaims ID トークンのクレーム
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce       string `json:"nonce"`
+	SlackUserID string `json:"https://slack.com/user_id"`
+	TeamID      string `json:"https://slack.com/team_id"`
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+}
+
+func NewOIDCProvider(cfg *config.Config) *OIDCProvider {
+	return &OIDCProvider{
+		issuer:       strings.TrimSuffix(cfg.OIDCIssuer, "/"),
+		clientID:     cfg.SlackClientID,
+		clientSecret: cfg.SlackClientSecret,
+		redirectURL:  cfg.OIDCRedirectURL,
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+		keys:         make(map[string]*rsa.PublicKey),
+	}
+}
+
+// AuthCodeURL ログイン画面（認可エンドポイント）のURL
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce string) (string, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("scope", "openid profile email")
+	q.Set("client_id", p.clientID)
+	q.Set("redirect_uri", p.redirectURL)
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	return d.AuthorizationEndpoint + "?" + q.Encode(), nil
+}
+
+// Exchange 認可コードを ID トークンに交換し、検証した上で利用者を返す
+func (p *OIDCProvider) Exchange(ctx context.Context, code, nonce string) (*model.SlackIdentity, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("client_id", p.clientID)
+	form.Set("client_secret", p.clientSecret)
+	form.Set("redirect_uri", p.redirectURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call token endpoint: %w", err)
+	}
+	defer res.Body.Close()
+
+	// Slack は HTTP 200 + ok:false、標準的なプロバイダは 4xx + error で失敗を返す
+	var body struct {
+		OK      *bool  `json:"ok"`
+		Error   string `json:"error"`
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if res.StatusCode != http.StatusOK || (body.OK != nil && !*body.OK) || body.Error != "" {
+		return nil, fmt.Errorf("token endpoint returned error: %s (status %d)", body.Error, res.StatusCode)
+	}
+	if body.IDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+
+	return p.verifyIDToken(ctx, d, body.IDToken, nonce)
+}
+
+// verifyIDToken 署名・発行者・対象・有効期限・nonce を検証する
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, d *oidcDiscovery, raw, nonce string) (*model.SlackIdentity, error) {
+	var claims idTokenClaims
+	_, err := jwt.ParseWithClaims(raw, &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.publicKey(ctx, d, kid)
+	},
+		jwt.WithValidMethods([]string{
+			jwt.SigningMethodRS256.Alg(), jwt.SigningMethodRS384.Alg(), jwt.SigningMethodRS512.Alg(),
+		}),
+		jwt.WithIssuer(d.Issuer),
+		jwt.WithAudience(p.clientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	if claims.Nonce != nonce {
+		return nil, errors.New("invalid id_token: nonce mismatch")
+	}
+
+	identity := &model.SlackIdentity{
+		SlackUserID: claims.SlackUserID,
+		TeamID:      claims.TeamID,
+		Name:        claims.Name,
+		Email:       claims.Email,
+	}
+	if identity.SlackUserID == "" {
+		// Slack 以外のプロバイダでは sub をそのまま使う
+		identity.SlackUserID = claims.Subject
+	}
+	return identity, nil
+}
+
+// getDiscovery discovery ドキュメントを取得する（取得できたらキャッシュ）
+func (p *OIDCProvider) getDiscovery(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+	if p.clientID == "" {
+		return nil, errors.New("SLACK_CLIENT_ID is not configured")
+	}
+
+	var d oidcDiscovery
+	if err := p.getJSON(ctx, p.issuer+"/.well-known/openid-configuration", &d); err != nil {
+		return nil, fmt.Errorf("failed to load openid configuration: %w", err)
+	}
+	// issuer が空だと ID トークンの発行者を検証できないので、必須にする
+	if d.Issuer == "" || d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, errors.New("openid configuration is incomplete")
+	}
+
+	p.discovery = &d
+	return p.discovery, nil
+}
+
+// publicKey kid の公開鍵を返す。知らない kid なら鍵の入れ替えとみなして JWKS を取り直す
+func (p *OIDCProvider) publicKey(ctx context.Context, d *oidcDiscovery, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+
+	var jwks struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := p.getJSON(ctx, d.JWKSURI, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to load jwks: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	p.keys = keys
+
+	key, ok := p.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key: %q", kid)
+	}
+	return key, nil
+}
+
+// getJSON GET してJSONをデコードする
+func (p *OIDCProvider) getJSON(ctx context.Context, endpoint string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", res.StatusCode, endpoint)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"seeft-slack-notification/internal/config"
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/repository"
+)
+
+// PreferenceService ユーザーごとの通知設定の取得・更新と、通知可否の判定を行う
+type PreferenceService struct {
+	prefRepo      *repository.NotificationPreferenceRepository
+	userRepo      *repository.UserRepository
+	calendar      *ShiftCalendar
+	activeWeather string
+}
+
+func NewPreferenceService(
+	cfg *config.Config,
+	prefRepo *repository.NotificationPreferenceRepository,
+	userRepo *repository.UserRepository,
+	calendar *ShiftCalendar,
+) *PreferenceService {
+	return &PreferenceService{
+		prefRepo:      prefRepo,
+		userRepo:      userRepo,
+		calendar:      calendar,
+		activeWeather: cfg.ActiveWeather,
+	}
+}
+
+// Get ユーザーの通知設定を取得（未保存なら既定値）
+func (s *PreferenceService) Get(userID int) (*model.NotificationPreference, error) {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, err
+	}
+
+	pref, err := s.prefRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if pref == nil {
+		return model.DefaultNotificationPreference(userID), nil
+	}
+	return pref, nil
+}
+
+// Update 通知設定を検証して保存する
+func (s *PreferenceService) Update(userID int, req model.NotificationPreferenceRequest) (*model.NotificationPreference, error) {
+	switch req.DeliveryChannel {
+	case model.DeliveryChannelDM, model.DeliveryChannelEmail, model.DeliveryChannelNone:
+	default:
+		return nil, fmt.Errorf("%w: invalid delivery_channel: %q", ErrInvalidInput, req.DeliveryChannel)
+	}
+	switch req.DeliveryMode {
+	case model.DeliveryModeRealtime, model.DeliveryModeDigest:
+	default:
+		return nil, fmt.Errorf("%w: invalid delivery_mode: %q", ErrInvalidInput, req.DeliveryMode)
+	}
+	if req.WithinHours < 0 {
+		return nil, fmt.Errorf("%w: within_hours must not be negative", ErrInvalidInput)
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.DeliveryChannel == model.DeliveryChannelEmail && user.Email == "" {
+		return nil, fmt.Errorf("%w: email address is not registered for user %d", ErrInvalidInput, userID)
+	}
+
+	pref := &model.NotificationPreference{
+		UserID:                 userID,
+		NotifyCreate:           req.NotifyCreate,
+		NotifyUpdate:           req.NotifyUpdate,
+		NotifyDelete:           req.NotifyDelete,
+		DeliveryChannel:        req.DeliveryChannel,
+		DeliveryMode:           req.DeliveryMode,
+		IncludeInactiveWeather: req.IncludeInactiveWeather,
+		WithinHours:            req.WithinHours,
+	}
+	if err := s.prefRepo.Upsert(pref); err != nil {
+		return nil, err
+	}
+	return pref, nil
+}
+
+// LoadAll 同期処理用に全ユーザーの設定をまとめて取得する
+func (s *PreferenceService) LoadAll() (map[int]*model.NotificationPreference, error) {
+	return s.prefRepo.GetAll()
+}
+
+// For マップから設定を取り出す（未保存なら既定値）
+func (s *PreferenceService) For(prefs map[int]*model.NotificationPreference, userID int) *model.NotificationPreference {
+	if p, ok := prefs[userID]; ok {
+		return p
+	}
+	return model.DefaultNotificationPreference(userID)
+}
+
+// Matches 変更内容が設定の条件（アクション種別・天気プラン・開始までの時間）に合うか
+// 送り先やタイミング(realtime/digest)はここでは見ない
+func (s *PreferenceService) Matches(pref *model.NotificationPreference, actionType, date string, timeID int, weather string, now time.Time) bool {
+	if !pref.AllowsAction(actionType) {
+		return false
+	}
+
+	// 実施しない方の天気プランの変更
+	if !pref.IncludeInactiveWeather && s.activeWeather != "" && weather != s.activeWeather {
+		return false
+	}
+
+	// シフト開始まで WithinHours 以上ある変更は送らない（日程が不明な場合は送る）
+	if pref.WithinHours > 0 {
+		if start, ok := s.calendar.StartTime(date, timeID); ok {
+			if start.Sub(now) > time.Duration(pref.WithinHours)*time.Hour {
+				return false
+			}
+		}
+	}
+
+	return true
+}
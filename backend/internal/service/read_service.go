@@ -0,0 +1,86 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/repository"
+)
+
+var (
+	ErrShiftNotFound = fmt.Errorf("shift %w", ErrNotFound)
+	ErrNotShiftOwner = errors.New("shift belongs to another user")
+)
+
+// ReadService シフト（とその変更通知）の既読管理
+// 既読にできるのは自分のシフトのみ。既読にしたら、他に開いている画面の未読件数も更新させる
+type ReadService struct {
+	shiftRepo     *repository.ShiftRepository
+	shiftReadRepo *repository.ShiftReadRepository
+	broker        *ChangeBroker
+}
+
+func NewReadService(shiftRepo *repository.ShiftRepository, shiftReadRepo *repository.ShiftReadRepository, broker *ChangeBroker) *ReadService {
+	return &ReadService{
+		shiftRepo:     shiftRepo,
+		shiftReadRepo: shiftReadRepo,
+		broker:        broker,
+	}
+}
+
+// MarkRead userID のシフトを1件既読にし、既読にした日時を返す
+func (s *ReadService) MarkRead(userID, shiftID int) (time.Time, error) {
+	shift, err := s.shiftRepo.GetByID(shiftID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if shift == nil {
+		return time.Time{}, ErrShiftNotFound
+	}
+	if shift.UserID != userID {
+		return time.Time{}, ErrNotShiftOwner
+	}
+
+	readAt, err := s.shiftReadRepo.MarkRead(shiftID, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	s.broker.Notify(userID)
+	return readAt, nil
+}
+
+// MarkReadBulk userID のシフトをまとめて既読にし、件数を返す
+// shift_ids に他人のシフトが含まれていたら、1件も既読にしない
+func (s *ReadService) MarkReadBulk(userID int, req model.MarkReadRequest) (int, error) {
+	if len(req.ShiftIDs) > 0 {
+		owners, err := s.shiftRepo.GetOwners(req.ShiftIDs)
+		if err != nil {
+			return 0, err
+		}
+		for _, id := range req.ShiftIDs {
+			owner, ok := owners[id]
+			if !ok {
+				return 0, fmt.Errorf("%w: id=%d", ErrShiftNotFound, id)
+			}
+			if owner != userID {
+				return 0, fmt.Errorf("%w: id=%d", ErrNotShiftOwner, id)
+			}
+		}
+	}
+
+	n, err := s.shiftReadRepo.MarkReadBulk(userID, req.ShiftIDs, req.Before)
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		s.broker.Notify(userID)
+	}
+	return n, nil
+}
+
+// UnreadCount 未読の変更の件数
+func (s *ReadService) UnreadCount(userID int) (int, error) {
+	return s.shiftReadRepo.CountUnread(userID)
+}
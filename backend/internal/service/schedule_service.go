@@ -0,0 +1,211 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/repository"
+)
+
+// ErrTaskNotFound 有効なシフトが1つも無いタスク
+var ErrTaskNotFound = fmt.Errorf("task %w", ErrNotFound)
+
+// ScheduleService スプレッドシートと同じ形（ユーザー × 時間枠）のスケジュール表や、タスク別の担当表を作る
+type ScheduleService struct {
+	shiftRepo *repository.ShiftRepository
+	calendar  *ShiftCalendar
+}
+
+func NewScheduleService(shiftRepo *repository.ShiftRepository, calendar *ShiftCalendar) *ScheduleService {
+	return &ScheduleService{
+		shiftRepo: shiftRepo,
+		calendar:  calendar,
+	}
+}
+
+// Grid スケジュール表を作る
+// 列はシフトがある最初の枠から最後の枠まで（途中の空き枠も含む）、タスク名は名前順
+func (s *ScheduleService) Grid(filter model.ScheduleGridFilter) (*model.ScheduleGrid, error) {
+	shifts, err := s.shiftRepo.GetGrid(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	grid := &model.ScheduleGrid{
+		YearID:  filter.YearID,
+		Date:    filter.Date,
+		Weather: filter.Weather,
+		Slots:   []model.GridSlot{},
+		Tasks:   []string{},
+		Rows:    []model.GridUserRow{},
+	}
+	if len(shifts) == 0 {
+		return grid, nil
+	}
+
+	minTimeID, maxTimeID := shifts[0].TimeID, shifts[0].TimeID
+	taskSet := make(map[string]bool)
+	for _, sh := range shifts {
+		if sh.TimeID < minTimeID {
+			minTimeID = sh.TimeID
+		}
+		if sh.TimeID > maxTimeID {
+			maxTimeID = sh.TimeID
+		}
+		taskSet[sh.TaskName] = true
+	}
+
+	for timeID := minTimeID; timeID <= maxTimeID; timeID++ {
+		grid.Slots = append(grid.Slots, model.GridSlot{
+			TimeID: timeID,
+			Start:  timeIDToString(timeID),
+			End:    timeIDToString(timeID + 1),
+		})
+	}
+
+	for task := range taskSet {
+		grid.Tasks = append(grid.Tasks, task)
+	}
+	sort.Strings(grid.Tasks)
+	taskIndex := make(map[string]int, len(grid.Tasks))
+	for i, task := range grid.Tasks {
+		taskIndex[task] = i
+	}
+
+	// シフトはユーザーごとに並んでいるので、ユーザーが変わったら行を追加する
+	var row *model.GridUserRow
+	for _, sh := range shifts {
+		if row == nil || row.UserID != sh.UserID {
+			grid.Rows = append(grid.Rows, model.GridUserRow{
+				UserID:   sh.UserID,
+				UserName: sh.UserName,
+				Group:    sh.UserGroup,
+				Cells:    make([]*int, len(grid.Slots)),
+			})
+			row = &grid.Rows[len(grid.Rows)-1]
+		}
+		index := taskIndex[sh.TaskName]
+		row.Cells[sh.TimeID-minTimeID] = &index
+	}
+
+	return grid, nil
+}
+
+// Search 条件に一致するシフトを検索する（担当者名と枠の開始・終了時刻付き）
+func (s *ScheduleService) Search(filter model.ShiftSearchFilter) (*model.ShiftSearchPage, error) {
+	shifts, total, err := s.shiftRepo.Search(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sh := range shifts {
+		sh.Start = timeIDToString(sh.TimeID)
+		sh.End = timeIDToString(sh.TimeID + 1)
+	}
+
+	return &model.ShiftSearchPage{
+		Shifts: shifts,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, nil
+}
+
+// Tasks タスクごとの割り当ての集計（yearID=0 なら全年度）
+func (s *ScheduleService) Tasks(yearID int) ([]*model.TaskSummary, error) {
+	summaries, err := s.shiftRepo.GetTaskSummaries(yearID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range summaries {
+		t.TotalHours = float64(t.SlotCount*MinutesStep) / 60
+	}
+	return summaries, nil
+}
+
+// Roster タスクの担当表を作る（yearID=0 なら全年度）
+// 日付・天気ごとに、誰かが割り当てられている連続した枠を1つの時間帯にまとめる
+func (s *ScheduleService) Roster(taskName string, yearID int) (*model.TaskRoster, error) {
+	shifts, err := s.shiftRepo.GetByTask(taskName, yearID)
+	if err != nil {
+		return nil, err
+	}
+	if len(shifts) == 0 {
+		return nil, ErrTaskNotFound
+	}
+
+	roster := buildRoster(taskName, shifts)
+
+	// 日付ラベルは文字列順ではなく開催日程の順に並べる
+	sort.SliceStable(roster.Days, func(i, j int) bool {
+		a, b := roster.Days[i], roster.Days[j]
+		if a.YearID != b.YearID {
+			return a.YearID < b.YearID
+		}
+		return s.calendar.CompareDates(a.Date, b.Date) < 0
+	})
+
+	return roster, nil
+}
+
+// buildRoster 年度・日付・天気・時間順に並んだシフトから担当表を作る（日付の並べ替えはしない）
+func buildRoster(taskName string, shifts []*model.TaskShift) *model.TaskRoster {
+	roster := &model.TaskRoster{TaskName: taskName, Days: []model.TaskRosterDay{}}
+
+	// シフトは日付・天気・時間順に並んでいるので、変わり目で日・時間帯・枠を追加する
+	var (
+		day   *model.TaskRosterDay
+		block *model.RosterBlock
+		slot  *model.RosterSlot
+	)
+	for _, sh := range shifts {
+		if day == nil || day.YearID != sh.YearID || day.Date != sh.Date || day.Weather != sh.Weather {
+			roster.Days = append(roster.Days, model.TaskRosterDay{
+				YearID:  sh.YearID,
+				Date:    sh.Date,
+				Weather: sh.Weather,
+				Blocks:  []model.RosterBlock{},
+			})
+			day = &roster.Days[len(roster.Days)-1]
+			block, slot = nil, nil
+		}
+
+		if slot == nil || slot.TimeID != sh.TimeID {
+			if slot == nil || sh.TimeID != slot.TimeID+1 {
+				day.Blocks = append(day.Blocks, model.RosterBlock{
+					Start:     timeIDToString(sh.TimeID),
+					Assignees: []model.RosterMember{},
+				})
+				block = &day.Blocks[len(day.Blocks)-1]
+			}
+			block.Slots = append(block.Slots, model.RosterSlot{
+				TimeID: sh.TimeID,
+				Start:  timeIDToString(sh.TimeID),
+				End:    timeIDToString(sh.TimeID + 1),
+			})
+			slot = &block.Slots[len(block.Slots)-1]
+			block.End = slot.End
+		}
+
+		member := model.RosterMember{UserID: sh.UserID, UserName: sh.UserName}
+		slot.Members = append(slot.Members, member)
+		slot.Headcount++
+		if !containsMember(block.Assignees, sh.UserID) {
+			block.Assignees = append(block.Assignees, member)
+		}
+	}
+
+	return roster
+}
+
+// containsMember 担当者の一覧にユーザーが含まれるか
+func containsMember(members []model.RosterMember, userID int) bool {
+	for _, m := range members {
+		if m.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
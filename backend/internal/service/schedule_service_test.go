@@ -0,0 +1,99 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"seeft-slack-notification/internal/model"
+)
+
+func TestBuildRoster(t *testing.T) {
+	sato := model.RosterMember{UserID: 1, UserName: "佐藤"}
+	tanaka := model.RosterMember{UserID: 2, UserName: "田中"}
+	suzuki := model.RosterMember{UserID: 3, UserName: "鈴木"}
+
+	tests := []struct {
+		name   string
+		shifts []*model.TaskShift // 年度・日付・天気・時間・ユーザー順
+		want   *model.TaskRoster
+	}{
+		{
+			name: "担当者がいなければ空",
+			want: &model.TaskRoster{TaskName: "救護", Days: []model.TaskRosterDay{}},
+		},
+		{
+			name: "連続した枠は1つの時間帯にまとめ、空いた枠で分ける",
+			shifts: []*model.TaskShift{
+				{YearID: 1, Date: "1日目", Weather: "晴れ", TimeID: 33, UserID: 1, UserName: "佐藤"},
+				{YearID: 1, Date: "1日目", Weather: "晴れ", TimeID: 33, UserID: 2, UserName: "田中"},
+				{YearID: 1, Date: "1日目", Weather: "晴れ", TimeID: 34, UserID: 1, UserName: "佐藤"},
+				{YearID: 1, Date: "1日目", Weather: "晴れ", TimeID: 36, UserID: 3, UserName: "鈴木"},
+			},
+			want: &model.TaskRoster{TaskName: "救護", Days: []model.TaskRosterDay{
+				{YearID: 1, Date: "1日目", Weather: "晴れ", Blocks: []model.RosterBlock{
+					{Start: "10:00", End: "11:00", Assignees: []model.RosterMember{sato, tanaka}, Slots: []model.RosterSlot{
+						{TimeID: 33, Start: "10:00", End: "10:30", Headcount: 2, Members: []model.RosterMember{sato, tanaka}},
+						{TimeID: 34, Start: "10:30", End: "11:00", Headcount: 1, Members: []model.RosterMember{sato}},
+					}},
+					{Start: "11:30", End: "12:00", Assignees: []model.RosterMember{suzuki}, Slots: []model.RosterSlot{
+						{TimeID: 36, Start: "11:30", End: "12:00", Headcount: 1, Members: []model.RosterMember{suzuki}},
+					}},
+				}},
+			}},
+		},
+		{
+			name: "天気が変わったら、時間が続いていても別の担当表",
+			shifts: []*model.TaskShift{
+				{YearID: 1, Date: "1日目", Weather: "晴れ", TimeID: 33, UserID: 1, UserName: "佐藤"},
+				{YearID: 1, Date: "1日目", Weather: "晴れ", TimeID: 34, UserID: 1, UserName: "佐藤"},
+				{YearID: 1, Date: "1日目", Weather: "雨", TimeID: 35, UserID: 1, UserName: "佐藤"},
+			},
+			want: &model.TaskRoster{TaskName: "救護", Days: []model.TaskRosterDay{
+				{YearID: 1, Date: "1日目", Weather: "晴れ", Blocks: []model.RosterBlock{
+					{Start: "10:00", End: "11:00", Assignees: []model.RosterMember{sato}, Slots: []model.RosterSlot{
+						{TimeID: 33, Start: "10:00", End: "10:30", Headcount: 1, Members: []model.RosterMember{sato}},
+						{TimeID: 34, Start: "10:30", End: "11:00", Headcount: 1, Members: []model.RosterMember{sato}},
+					}},
+				}},
+				{YearID: 1, Date: "1日目", Weather: "雨", Blocks: []model.RosterBlock{
+					{Start: "11:00", End: "11:30", Assignees: []model.RosterMember{sato}, Slots: []model.RosterSlot{
+						{TimeID: 35, Start: "11:00", End: "11:30", Headcount: 1, Members: []model.RosterMember{sato}},
+					}},
+				}},
+			}},
+		},
+		{
+			name: "日付が変わったら、同じ時間帯でも別の担当表",
+			shifts: []*model.TaskShift{
+				{YearID: 1, Date: "1日目", Weather: "晴れ", TimeID: 34, UserID: 2, UserName: "田中"},
+				{YearID: 1, Date: "2日目", Weather: "晴れ", TimeID: 34, UserID: 2, UserName: "田中"},
+				{YearID: 1, Date: "2日目", Weather: "晴れ", TimeID: 35, UserID: 3, UserName: "鈴木"},
+			},
+			want: &model.TaskRoster{TaskName: "救護", Days: []model.TaskRosterDay{
+				{YearID: 1, Date: "1日目", Weather: "晴れ", Blocks: []model.RosterBlock{
+					{Start: "10:30", End: "11:00", Assignees: []model.RosterMember{tanaka}, Slots: []model.RosterSlot{
+						{TimeID: 34, Start: "10:30", End: "11:00", Headcount: 1, Members: []model.RosterMember{tanaka}},
+					}},
+				}},
+				{YearID: 1, Date: "2日目", Weather: "晴れ", Blocks: []model.RosterBlock{
+					{Start: "10:30", End: "11:30", Assignees: []model.RosterMember{tanaka, suzuki}, Slots: []model.RosterSlot{
+						{TimeID: 34, Start: "10:30", End: "11:00", Headcount: 1, Members: []model.RosterMember{tanaka}},
+						{TimeID: 35, Start: "11:00", End: "11:30", Headcount: 1, Members: []model.RosterMember{suzuki}},
+					}},
+				}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildRoster("救護", tt.shifts)
+			if !reflect.DeepEqual(got, tt.want) {
+				gotJSON, _ := json.MarshalIndent(got, "", "  ")
+				wantJSON, _ := json.MarshalIndent(tt.want, "", "  ")
+				t.Errorf("roster =\n%s\nwant\n%s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
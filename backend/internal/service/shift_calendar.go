@@ -0,0 +1,91 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"seeft-slack-notification/internal/config"
+)
+
+// ShiftCalendar 日付ラベルとtimeIDから、シフトの実際の日時を求める
+type ShiftCalendar struct {
+	dates    map[string]time.Time // 日付ラベル -> その日の0時
+	location *time.Location
+}
+
+func NewShiftCalendar(cfg *config.Config) *ShiftCalendar {
+	return &ShiftCalendar{
+		dates:    cfg.EventDates,
+		location: cfg.EventLocation,
+	}
+}
+
+// timeIDToClock timeIDを開始時刻(時, 分)に変換する
+// timeID=25 が 6:00 で、1つ増えるごとに30分進む
+func timeIDToClock(timeID int) (int, int) {
+	minutes := BaseHour*60 + (timeID-BaseTimeID)*MinutesStep
+	return minutes / 60, minutes % 60
+}
+
+// timeIDToString timeIDを "06:30" 形式の文字列に変換する
+func timeIDToString(timeID int) string {
+	hours, minutes := timeIDToClock(timeID)
+	return fmt.Sprintf("%02d:%02d", hours, minutes)
+}
+
+// ClockToTimeID "06:30" 形式の時刻を、その時刻に始まる枠のtimeIDに変換する（枠の境目でなければエラー）
+func ClockToTimeID(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid time: %s", ErrInvalidInput, clock)
+	}
+
+	minutes := t.Hour()*60 + t.Minute() - BaseHour*60
+	if minutes < 0 || minutes%MinutesStep != 0 {
+		return 0, fmt.Errorf("%w: time must be on a %d-minute boundary from %02d:00: %s", ErrInvalidInput, MinutesStep, BaseHour, clock)
+	}
+	return BaseTimeID + minutes/MinutesStep, nil
+}
+
+// StartTime シフト枠の開始日時（日付ラベルが日程に無ければ ok=false）
+func (c *ShiftCalendar) StartTime(date string, timeID int) (time.Time, bool) {
+	day, ok := c.dates[date]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	hours, minutes := timeIDToClock(timeID)
+	return day.Add(time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute), true
+}
+
+// EndTime シフト枠の終了日時（1枠 = MinutesStep 分）
+func (c *ShiftCalendar) EndTime(date string, timeID int) (time.Time, bool) {
+	start, ok := c.StartTime(date, timeID)
+	if !ok {
+		return time.Time{}, false
+	}
+	return start.Add(MinutesStep * time.Minute), true
+}
+
+// Location 開催地のタイムゾーン
+func (c *ShiftCalendar) Location() *time.Location {
+	return c.location
+}
+
+// CompareDates 日付ラベルを開催日程の順に比較する（日程に無いラベルは後ろ、同士は文字列順）
+func (c *ShiftCalendar) CompareDates(a, b string) int {
+	da, okA := c.dates[a]
+	db, okB := c.dates[b]
+
+	switch {
+	case okA && okB:
+		return da.Compare(db)
+	case okA:
+		return -1
+	case okB:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
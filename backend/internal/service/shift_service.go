@@ -5,18 +5,29 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"time"
 
 	"seeft-slack-notification/internal/model"
 	"seeft-slack-notification/internal/repository"
 )
 
 type ShiftService struct {
-	db            *sql.DB
-	shiftRepo     *repository.ShiftRepository
-	userRepo      *repository.UserRepository
-	actionLogRepo *repository.ActionLogRepository
-	slackService  *SlackService // ★追加: Slack通知用サービス
-	shiftReadRepo *repository.ShiftReadRepository
+	db              *sql.DB
+	shiftRepo       *repository.ShiftRepository
+	userRepo        *repository.UserRepository
+	actionLogRepo   *repository.ActionLogRepository
+	slackService    *SlackService // ★追加: Slack通知用サービス
+	shiftReadRepo   *repository.ShiftReadRepository
+	prefService     *PreferenceService // ユーザーごとの通知設定
+	emailService    *EmailService      // メール通知用サービス
+	leadService     *TaskLeadService   // タスクリーダーへの人員変更通知
+	staffingService *StaffingService   // 必要人数との比較・人員不足アラート
+	syncRunRepo     *repository.SyncRunRepository
+	deliveryService *DeliveryService       // 通知ごとの配信記録
+	debouncer       *NotificationDebouncer // 同じ枠への短時間の変更をまとめる
+	broker          *ChangeBroker          // アプリへのリアルタイム配信(SSE)
+	availability    *AvailabilityService   // NGを申告した枠への割り当ての確認
+	swapRepo        *repository.ShiftSwapRepository
 }
 
 // NewShiftService コンストラクタ
@@ -27,31 +38,70 @@ func NewShiftService(
 	logRepo *repository.ActionLogRepository,
 	slackService *SlackService, // ★引数に追加
 	shiftReadRepo *repository.ShiftReadRepository,
+	prefService *PreferenceService,
+	emailService *EmailService,
+	leadService *TaskLeadService,
+	staffingService *StaffingService,
+	syncRunRepo *repository.SyncRunRepository,
+	deliveryService *DeliveryService,
+	debouncer *NotificationDebouncer,
+	broker *ChangeBroker,
+	availability *AvailabilityService,
+	swapRepo *repository.ShiftSwapRepository,
 ) *ShiftService {
 	return &ShiftService{
-		db:            db,
-		shiftRepo:     shiftRepo,
-		userRepo:      userRepo,
-		actionLogRepo: logRepo,
-		slackService:  slackService,
-		shiftReadRepo: shiftReadRepo,
+		db:              db,
+		shiftRepo:       shiftRepo,
+		userRepo:        userRepo,
+		actionLogRepo:   logRepo,
+		slackService:    slackService,
+		shiftReadRepo:   shiftReadRepo,
+		prefService:     prefService,
+		emailService:    emailService,
+		leadService:     leadService,
+		staffingService: staffingService,
+		syncRunRepo:     syncRunRepo,
+		deliveryService: deliveryService,
+		debouncer:       debouncer,
+		broker:          broker,
+		availability:    availability,
+		swapRepo:        swapRepo,
 	}
 }
 
 // SyncShifts GASからのデータを元に、DBを完全同期（作成・更新・削除）する
-func (s *ShiftService) SyncShifts(gasChanges []model.ShiftChange) error {
+// 結果の同期IDで、この同期で発生した通知の配信状況を後から確認できる
+// NGを申告した枠にタスクが割り当てられていても同期は行い、結果の警告として返す
+func (s *ShiftService) SyncShifts(gasChanges []model.ShiftChange) (*model.SyncResult, error) {
 	// 1. トランザクション開始
 	tx, err := s.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	// 同期・交換の反映はコミットまで1つずつ（ここで他の同期を待つ）
+	syncID, err := s.syncRunRepo.Create(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// コミット後に送る通知と、記録した変更の件数・変更があったユーザー
+	var pending []*pendingNotification
+	changeCount := 0
+	changedUsers := make(map[int]struct{})
+
 	// 2. 準備: ユーザー情報を全取得してマップ化 (名前 -> User構造体)
 	// 通知用にSlackUserIDも必要なので、IDだけではなくUserごと取得します
 	nameToUserMap, err := s.preloadUserMap()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// 通知設定も一括で取得しておく (ユーザーID -> 設定)
+	prefs, err := s.prefService.LoadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notification preferences: %w", err)
 	}
 
 	// 削除通知用に ID -> User のマップも作っておく
@@ -63,9 +113,12 @@ func (s *ShiftService) SyncShifts(gasChanges []model.ShiftChange) error {
 	// 3. 準備: 現在の有効なシフトを全取得してマップ化 (Key -> Shift)
 	currentShifts, err := s.shiftRepo.GetAll()
 	if err != nil {
-		return fmt.Errorf("failed to get all shifts: %w", err)
+		return nil, fmt.Errorf("failed to get all shifts: %w", err)
 	}
 
+	// タスクリーダー通知・人員不足アラート用に、枠ごとの人数の増減を記録する
+	staffing := NewStaffingTracker(currentShifts)
+
 	// Key: "YearID-TimeID-Date-UserID"
 	currentShiftMap := make(map[string]*model.Shift)
 	for _, shift := range currentShifts {
@@ -73,7 +126,18 @@ func (s *ShiftService) SyncShifts(gasChanges []model.ShiftChange) error {
 		currentShiftMap[key] = shift
 	}
 
+	// 承認済みでシートに書き戻していない交換の枠は、シートがまだ古いので触らない
+	// （同期すると交換が元に戻ってしまう。GASが書き戻して ack すれば次の同期から対象になる）
+	pendingSwapIDs, swappedKeys, err := s.unexportedSwapKeys()
+	if err != nil {
+		return nil, err
+	}
+	for key := range swappedKeys {
+		delete(currentShiftMap, key)
+	}
+
 	// 4. GASデータ(gasChanges)をループして「新規」か「更新」を処理
+	seenKeys := make(map[string]struct{})
 	for _, change := range gasChanges {
 		// ユーザー名からUser情報を取得
 		user, ok := nameToUserMap[change.UserName]
@@ -84,6 +148,17 @@ func (s *ShiftService) SyncShifts(gasChanges []model.ShiftChange) error {
 
 		key := makeKey(change.YearID, change.TimeID, change.Date, user.ID)
 
+		if _, swapped := swappedKeys[key]; swapped {
+			continue
+		}
+
+		// 改名前と後の名前が両方シートにあると、同じ枠が2回来る（先に来た方を使う）
+		if _, dup := seenKeys[key]; dup {
+			log.Printf("Warning: Duplicate shift for user %s (as %s): %s", user.Name, change.UserName, key)
+			continue
+		}
+		seenKeys[key] = struct{}{}
+
 		if oldShift, exists := currentShiftMap[key]; exists {
 			// --- 【更新パターン】DBに既に存在する ---
 
@@ -95,13 +170,24 @@ func (s *ShiftService) SyncShifts(gasChanges []model.ShiftChange) error {
 
 				// DB更新
 				if err := s.shiftRepo.Update(tx, &newShift); err != nil {
-					return fmt.Errorf("failed to update shift: %w", err)
+					return nil, fmt.Errorf("failed to update shift: %w", err)
 				}
 
-				// ログ保存 & Slack通知
-				if err := s.logAction(tx, oldShift.ID, "UPDATE", oldShift, &newShift, user); err != nil {
-					return err
+				// 変更されたシフトは未読に戻す
+				if err := s.shiftReadRepo.Upsert(tx, oldShift.ID, user.ID, false); err != nil {
+					return nil, err
 				}
+
+				staffing.RecordTransition(oldShift, &newShift, user)
+
+				// ログ保存 & 通知の準備
+				n, err := s.logAction(tx, syncID, oldShift.ID, "UPDATE", oldShift, &newShift, user, s.prefService.For(prefs, user.ID))
+				if err != nil {
+					return nil, err
+				}
+				pending = appendPending(pending, n)
+				changeCount++
+				changedUsers[user.ID] = struct{}{}
 			}
 
 			// 処理済みとしてマップから消す
@@ -121,18 +207,24 @@ func (s *ShiftService) SyncShifts(gasChanges []model.ShiftChange) error {
 
 			// DB作成 (Create内でnewShift.IDがセットされる想定)
 			if err := s.shiftRepo.Create(tx, newShift); err != nil {
-				return fmt.Errorf("failed to create shift: %w", err)
+				return nil, fmt.Errorf("failed to create shift: %w", err)
 			}
 
 			// ★追加: 既読レコードを「未読(false)」で作成
 			if err := s.shiftReadRepo.Upsert(tx, newShift.ID, user.ID, false); err != nil {
-				return err
+				return nil, err
 			}
 
-			// ログ保存 & Slack通知
-			if err := s.logAction(tx, newShift.ID, "CREATE", nil, newShift, user); err != nil {
-				return err
+			staffing.RecordTransition(nil, newShift, user)
+
+			// ログ保存 & 通知の準備
+			n, err := s.logAction(tx, syncID, newShift.ID, "CREATE", nil, newShift, user, s.prefService.For(prefs, user.ID))
+			if err != nil {
+				return nil, err
 			}
+			pending = appendPending(pending, n)
+			changeCount++
+			changedUsers[user.ID] = struct{}{}
 		}
 	}
 
@@ -140,7 +232,7 @@ func (s *ShiftService) SyncShifts(gasChanges []model.ShiftChange) error {
 	for _, deletedShift := range currentShiftMap {
 		// 論理削除
 		if err := s.shiftRepo.Delete(tx, deletedShift.ID); err != nil {
-			return fmt.Errorf("failed to delete shift ID %d: %w", deletedShift.ID, err)
+			return nil, fmt.Errorf("failed to delete shift ID %d: %w", deletedShift.ID, err)
 		}
 
 		// 削除対象のユーザー情報を取得
@@ -151,18 +243,52 @@ func (s *ShiftService) SyncShifts(gasChanges []model.ShiftChange) error {
 			continue
 		}
 
-		// ログ保存 & Slack通知
-		if err := s.logAction(tx, deletedShift.ID, "DELETE", deletedShift, nil, user); err != nil {
-			return fmt.Errorf("failed to log delete action: %w", err)
+		staffing.RecordTransition(deletedShift, nil, user)
+
+		// ログ保存 & 通知の準備
+		n, err := s.logAction(tx, syncID, deletedShift.ID, "DELETE", deletedShift, nil, user, s.prefService.For(prefs, user.ID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to log delete action: %w", err)
 		}
+		pending = appendPending(pending, n)
+		changeCount++
+		changedUsers[user.ID] = struct{}{}
+	}
+
+	if err := s.syncRunRepo.Finish(tx, syncID, changeCount); err != nil {
+		return nil, err
 	}
 
 	// 6. 全ての処理が成功したので、コミット（保存確定）
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// 7. コミットできたので、溜めておいた通知を送る
+	// 同じ枠が短時間に何度も変わることがあるので、少し保留してからまとめて送る
+	for _, n := range pending {
+		s.debouncer.Hold(n)
+	}
+
+	// アプリで開いている画面(SSE)に新しい変更を知らせる
+	for userID := range changedUsers {
+		s.broker.Notify(userID)
+	}
+
+	// 8. タスクリーダーへ担当タスクの人員変更をまとめて通知
+	s.leadService.NotifyStaffingChanges(syncID, staffing, idToUserMap)
+
+	// 9. 必要人数を割った枠・不足が解消した枠をアラート
+	s.staffingService.CheckAfterSync(syncID, staffing)
+
+	// 10. NGを申告した枠への割り当てを警告する
+	conflicts := s.availability.Conflicts(gasChanges)
+	for _, c := range conflicts {
+		log.Printf("Warning: %s is assigned to %s at %d %s %s (%s), which is marked NG",
+			c.UserName, c.TaskName, c.YearID, c.Date, timeIDToString(c.TimeID), c.Weather)
 	}
 
-	return nil
+	return &model.SyncResult{SyncID: syncID, NGConflicts: conflicts, PendingSwapIDs: pendingSwapIDs}, nil
 }
 
 // --- 以下、ヘルパー関数 ---
@@ -172,6 +298,27 @@ func makeKey(year, time int, date string, user int) string {
 	return fmt.Sprintf("%d-%d-%s-%d", year, time, date, user)
 }
 
+// unexportedSwapKeys 承認済みでシートに書き戻していない交換のIDと、その交換で変わる枠（makeKey の形）
+// 交換した2人それぞれについて、両方の枠が対象になる
+func (s *ShiftService) unexportedSwapKeys() ([]int, map[string]struct{}, error) {
+	swaps, err := s.swapRepo.GetUnexported()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]int, 0, len(swaps))
+	keys := make(map[string]struct{})
+	for _, w := range swaps {
+		ids = append(ids, w.ID)
+		for _, slot := range []model.SwapSide{w.Requester, w.Counterpart} {
+			for _, userID := range []int{w.Requester.UserID, w.Counterpart.UserID} {
+				keys[makeKey(slot.YearID, slot.TimeID, slot.Date, userID)] = struct{}{}
+			}
+		}
+	}
+	return ids, keys, nil
+}
+
 // preloadUserMap 全ユーザーを取得して 名前->User構造体 のマップを作る
 func (s *ShiftService) preloadUserMap() (map[string]*model.User, error) {
 	// さっき作った GetAll をここで呼ぶ！
@@ -183,23 +330,44 @@ func (s *ShiftService) preloadUserMap() (map[string]*model.User, error) {
 	// 使いやすい辞書（マップ）に変換する
 	// "田中" -> {ID: 1, Name: "田中", SlackID: "U12345..."}
 	m := make(map[string]*model.User)
+	byID := make(map[int]*model.User)
 	for _, u := range users {
 		m[u.Name] = u
+		byID[u.ID] = u
+	}
+
+	// 改名したユーザーは、シートが古い名前のままでも同じユーザーとして扱う（今の名前が優先）
+	aliases, err := s.userRepo.GetAliases()
+	if err != nil {
+		return nil, err
+	}
+	for name, userID := range aliases {
+		if _, taken := m[name]; taken {
+			continue
+		}
+		if u, ok := byID[userID]; ok {
+			m[name] = u
+		}
 	}
 
 	return m, nil
 }
 
-// logAction 変更履歴を保存し、Slack通知キューに追加する
+// logAction 変更履歴と配信記録を保存し、すぐ送るべき通知があれば返す
 // 引数に user (*model.User) を追加しました
-func (s *ShiftService) logAction(tx *sql.Tx, shiftID int, actionType string, oldVal, newVal *model.Shift, user *model.User) error {
+// 変更履歴は通知設定に関係なく必ず保存する（digest はこの履歴から作られる）
+func (s *ShiftService) logAction(tx *sql.Tx, syncID, shiftID int, actionType string, oldVal, newVal *model.Shift, user *model.User, pref *model.NotificationPreference) (*pendingNotification, error) {
 	// 1. DB用: 差分Payloadの作成
 	diff := map[string]interface{}{}
 
 	if actionType == "UPDATE" {
-		diff["changes"] = []map[string]string{
+		changes := []map[string]string{
 			{"field": "task_name", "old": oldVal.TaskName, "new": newVal.TaskName},
 		}
+		if oldVal.Weather != newVal.Weather {
+			changes = append(changes, map[string]string{"field": "weather", "old": oldVal.Weather, "new": newVal.Weather})
+		}
+		diff["changes"] = changes
 	} else if actionType == "CREATE" {
 		diff["new_task"] = newVal.TaskName
 	} else if actionType == "DELETE" {
@@ -208,18 +376,19 @@ func (s *ShiftService) logAction(tx *sql.Tx, shiftID int, actionType string, old
 
 	payloadJSON, err := json.Marshal(diff)
 	if err != nil {
-		return fmt.Errorf("failed to marshal diff: %w", err)
+		return nil, fmt.Errorf("failed to marshal diff: %w", err)
 	}
 
 	// DBにログ保存
-	if err := s.actionLogRepo.Create(tx, shiftID, actionType, payloadJSON); err != nil {
-		return err
+	actionLogID, err := s.actionLogRepo.Create(tx, syncID, shiftID, actionType, payloadJSON)
+	if err != nil {
+		return nil, err
 	}
 
 	// 2. Slack通知用: データの準備
 	// DELETEの場合は newVal が nil なので、oldVal から情報を取る必要がある
 	var targetShift *model.Shift
-	var taskName, oldTaskName string
+	var taskName, oldTaskName, oldWeather string
 
 	if newVal != nil {
 		targetShift = newVal
@@ -231,6 +400,7 @@ func (s *ShiftService) logAction(tx *sql.Tx, shiftID int, actionType string, old
 
 	if oldVal != nil {
 		oldTaskName = oldVal.TaskName
+		oldWeather = oldVal.Weather
 	}
 
 	notificationPayload := NotificationPayload{
@@ -242,10 +412,53 @@ func (s *ShiftService) logAction(tx *sql.Tx, shiftID int, actionType string, old
 		Weather:     targetShift.Weather,
 		TaskName:    taskName,
 		OldTaskName: oldTaskName,
+		OldWeather:  oldWeather,
 	}
 
-	// Slack通知キューに放り込む (非同期)
-	s.slackService.EnqueueNotification(notificationPayload)
+	// 3. 通知設定から、送るか・いつ送るかを決める
+	// 除外される変更は suppressed、digest(まとめ送信)は次回のまとめ送信まで queued のまま
+	delivery := &model.NotificationDelivery{
+		ActionLogID: &actionLogID,
+		SyncID:      &syncID,
+		ShiftID:     &shiftID,
+		UserID:      &user.ID,
+		Kind:        actionType,
+		Channel:     pref.DeliveryChannel,
+		Recipient:   user.SlackUserID,
+		Status:      model.DeliveryStatusQueued,
+	}
+	if pref.DeliveryChannel == model.DeliveryChannelEmail {
+		delivery.Recipient = user.Email
+	}
+
+	matched := s.prefService.Matches(pref, actionType, targetShift.Date, targetShift.TimeID, targetShift.Weather, time.Now())
+	if pref.DeliveryChannel == model.DeliveryChannelNone || !matched {
+		delivery.Status = model.DeliveryStatusSuppressed
+	}
 
-	return nil
+	if err := s.deliveryService.Record(tx, delivery); err != nil {
+		return nil, err
+	}
+
+	if delivery.Status != model.DeliveryStatusQueued || pref.DeliveryMode != model.DeliveryModeRealtime {
+		return nil, nil
+	}
+
+	notificationPayload.OnResult = s.deliveryService.ResultHandler(delivery.ID)
+	return &pendingNotification{
+		userID:     user.ID,
+		slot:       slotKeyOf(targetShift),
+		deliveryID: delivery.ID,
+		route:      pref.DeliveryChannel,
+		email:      user.Email,
+		payload:    notificationPayload,
+	}, nil
+}
+
+// appendPending nilでなければ追加する
+func appendPending(pending []*pendingNotification, n *pendingNotification) []*pendingNotification {
+	if n == nil {
+		return pending
+	}
+	return append(pending, n)
 }
@@ -1,12 +1,19 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"log"
+	"strings"
+	"sync"
+	"time"
 
 	"seeft-slack-notification/internal/config"
 
 	"github.com/slack-go/slack"
+	"golang.org/x/time/rate"
 )
 
 // NotificationPayload 通知に必要なデータの塊
@@ -19,57 +26,288 @@ type NotificationPayload struct {
 	Weather     string
 	TaskName    string // 新しいタスク名（削除の場合は空）
 	OldTaskName string // 古いタスク名（新規の場合は空）
+	OldWeather  string // 古い天気（新規の場合は空）
+
+	// 指定された場合は本人へのDMではなく、このチャンネルに送る
+	ChannelID string
+
+	// 指定された場合は buildMessageBlocks を使わず、このメッセージをそのまま送る
+	// （タスクリーダー向けのまとめなど）
+	Text   string
+	Blocks []slack.Block
+
+	// 送信結果の受け取り先（配信記録の更新など）。nilなら何もしない
+	OnResult func(DeliveryResult)
+}
+
+// weatherChanged 更新で天気が変わったか
+func (p NotificationPayload) weatherChanged() bool {
+	return p.ActionType == "UPDATE" && p.OldWeather != "" && p.OldWeather != p.Weather
+}
+
+// weatherText 天気の表示（天気が変わった場合は format で変更前後を並べる）
+func (p NotificationPayload) weatherText(format string) string {
+	if p.weatherChanged() {
+		return fmt.Sprintf(format, p.OldWeather, p.Weather)
+	}
+	return p.Weather
+}
+
+// report 送信結果を OnResult に渡す
+func (p NotificationPayload) report(r DeliveryResult) {
+	if p.OnResult != nil {
+		p.OnResult(r)
+	}
 }
 
 type SlackService struct {
-	client            *slack.Client
-	channelID         string
-	notificationQueue chan NotificationPayload // ★これが「キュー」です
+	client    *slack.Client
+	channelID string
+
+	// ★これが「キュー」です
+	// 宛先ごとに同じキュー(=同じワーカー)へ振り分けることで、
+	// 同じユーザーへの CREATE → DELETE などが逆順で届かないようにする
+	queues []chan NotificationPayload
+
+	// プール全体で共有するレート制限
+	limiter      *rate.Limiter
+	backoffMu    sync.Mutex
+	backoffUntil time.Time // Slackから429が返ってきた場合、この時刻まで全ワーカーが待つ
+
+	// ワーカーの停止制御用
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mu     sync.RWMutex // closed とキューのクローズを保護する
+	closed bool
 }
 
 const (
-	BaseTimeID  = 25
-	BaseHour    = 6
-	MinutesStep = 30
-	QueueSize   = 100 // 一度に溜められる通知の数
+	BaseTimeID      = 25
+	BaseHour        = 6
+	MinutesStep     = 30
+	QueueSize       = 100 // ワーカー1つあたりに溜められる通知の数
+	MaxSendAttempts = 3   // 429(rate limited)時の最大試行回数
 )
 
 func NewSlackService(cfg *config.Config) *SlackService {
+	workerCount := cfg.SlackWorkerCount
+	if workerCount < 1 {
+		workerCount = 1
+	}
+
+	var options []slack.Option
+	if cfg.SlackAPIURL != "" {
+		// 開発用のフェイクサーバーなどに向ける（末尾の "/" は必須）
+		apiURL := cfg.SlackAPIURL
+		if !strings.HasSuffix(apiURL, "/") {
+			apiURL += "/"
+		}
+		options = append(options, slack.OptionAPIURL(apiURL))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
 	s := &SlackService{
-		client:            slack.New(cfg.SlackBotToken),
-		channelID:         cfg.SlackChannelID,
-		notificationQueue: make(chan NotificationPayload, QueueSize),
+		client:    slack.New(cfg.SlackBotToken, options...),
+		channelID: cfg.SlackChannelID,
+		queues:    make([]chan NotificationPayload, workerCount),
+		limiter:   rate.NewLimiter(rate.Limit(cfg.SlackRateLimit), workerCount),
+		ctx:       ctx,
+		cancel:    cancel,
 	}
 
-	// ★裏で動く「配送係」を起動する
-	go s.runWorker()
+	// ★裏で動く「配送係」をワーカー数だけ起動する
+	for i := range s.queues {
+		s.queues[i] = make(chan NotificationPayload, QueueSize)
+		s.wg.Add(1)
+		go s.runWorker(s.queues[i])
+	}
 
 	return s
 }
 
 // EnqueueNotification 通知をキューに追加する（呼び出し元は待たされない）
 func (s *SlackService) EnqueueNotification(payload NotificationPayload) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// 停止処理が始まった後は受け付けない（closeしたチャネルへの送信を防ぐ）
+	if s.closed {
+		log.Printf("Error: Slack service is shutting down, dropping message for %s", payload.UserName)
+		payload.report(DeliveryResult{Err: errShuttingDown})
+		return
+	}
+
 	select {
-	case s.notificationQueue <- payload:
+	case s.queueFor(payload) <- payload:
 		// キューに入れたらすぐ戻る
 	default:
 		// キューが満杯ならログを出して諦める（ブロッキング防止）
 		log.Println("Error: Slack notification queue is full, dropping message")
+		payload.report(DeliveryResult{Err: errQueueFull})
+	}
+}
+
+// queueFor 宛先ユーザーから担当キューを決める
+func (s *SlackService) queueFor(p NotificationPayload) chan NotificationPayload {
+	key := p.SlackUserID
+	if p.ChannelID != "" {
+		key = p.ChannelID
+	} else if key == "" {
+		key = p.UserName
 	}
+
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return s.queues[h.Sum32()%uint32(len(s.queues))]
 }
 
-// runWorker キューから取り出して送信する（裏方）
-func (s *SlackService) runWorker() {
-	for payload := range s.notificationQueue {
-		if err := s.send(payload); err != nil {
-			log.Printf("Failed to send slack notification: %v", err)
+// Shutdown 新規の受付を止め、キューに残った通知を ctx の期限まで送り切る
+// 期限を過ぎた場合はワーカーを止め、送れなかった件数をログに残す
+func (s *SlackService) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	for _, q := range s.queues {
+		close(q) // ワーカーは残りを取り出し終えたら終了する
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		// 期限切れ: 送信中のリクエストごとワーカーを止める
+		s.cancel()
+		<-done
+
+		dropped := 0
+		for _, q := range s.queues {
+			for payload := range q {
+				log.Printf("Slack notification dropped on shutdown: %s %s %s (user %s)",
+					payload.ActionType, payload.Date, s.timeIDToTimeString(payload.TimeID), payload.UserName)
+				payload.report(DeliveryResult{Err: errShuttingDown})
+				dropped++
+			}
 		}
+		return fmt.Errorf("slack queue drain timed out, %d notifications dropped: %w", dropped, ctx.Err())
+	}
+}
+
+// runWorker 担当キューから取り出して送信する（裏方）
+func (s *SlackService) runWorker(queue <-chan NotificationPayload) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case payload, ok := <-queue:
+			if !ok {
+				return // キューが閉じられ、全て送り切った
+			}
+			ts, attempts, err := s.send(s.ctx, payload)
+			if err != nil {
+				log.Printf("Failed to send slack notification: %v", err)
+			}
+			payload.report(DeliveryResult{TS: ts, Attempts: attempts, Err: err})
+		}
+	}
+}
+
+// postMessage レート制限を守りながら chat.postMessage を呼ぶ
+// 429 が返ってきた場合は Retry-After の間プール全体を止めてから再送する
+// 戻り値は (ts, 試行回数, error)
+func (s *SlackService) postMessage(ctx context.Context, channelID string, options ...slack.MsgOption) (string, int, error) {
+	var lastErr error
+	attempt := 0
+	for attempt < MaxSendAttempts {
+		if err := s.waitBackoff(ctx); err != nil {
+			return "", attempt, err
+		}
+		if err := s.limiter.Wait(ctx); err != nil {
+			return "", attempt, err
+		}
+
+		attempt++
+		_, ts, err := s.client.PostMessageContext(ctx, channelID, options...)
+		if err == nil {
+			return ts, attempt, nil
+		}
+
+		var rateErr *slack.RateLimitedError
+		if !errors.As(err, &rateErr) {
+			return "", attempt, err
+		}
+		log.Printf("Slack rate limited, retrying after %s (attempt %d/%d)", rateErr.RetryAfter, attempt, MaxSendAttempts)
+		s.setBackoff(rateErr.RetryAfter)
+		lastErr = err
+	}
+	return "", attempt, lastErr
+}
+
+// PostBlocks ブロックメッセージを同期的に送信し、投稿の ts を返す
+// まとめ送信などキューを通さない送信用（レート制限はワーカーと共有する）
+func (s *SlackService) PostBlocks(ctx context.Context, channelID, fallbackText string, blocks []slack.Block) (string, error) {
+	ts, _, err := s.postMessage(
+		ctx,
+		channelID,
+		slack.MsgOptionText(fallbackText, false),
+		slack.MsgOptionBlocks(blocks...),
+	)
+	if err != nil {
+		return "", fmt.Errorf("post message error: %w", err)
+	}
+	return ts, nil
+}
+
+// setBackoff 全ワーカー共通の待機時刻を延ばす
+func (s *SlackService) setBackoff(d time.Duration) {
+	s.backoffMu.Lock()
+	defer s.backoffMu.Unlock()
+
+	if until := time.Now().Add(d); until.After(s.backoffUntil) {
+		s.backoffUntil = until
+	}
+}
+
+// waitBackoff 429 による待機中であれば解除されるまで待つ
+func (s *SlackService) waitBackoff(ctx context.Context) error {
+	s.backoffMu.Lock()
+	wait := time.Until(s.backoffUntil)
+	s.backoffMu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
 }
 
 // send 実際にSlackに送信する内部関数
-func (s *SlackService) send(p NotificationPayload) error {
-	blocks := s.buildMessageBlocks(p)
+// 戻り値は (投稿の ts, 試行回数, error)
+func (s *SlackService) send(ctx context.Context, p NotificationPayload) (string, int, error) {
+	blocks := p.Blocks
+	if blocks == nil {
+		blocks = s.buildMessageBlocks(p)
+	}
 
 	// 1. チャンネルに送信
 	// _, _, err := s.client.PostMessage(
@@ -80,19 +318,34 @@ func (s *SlackService) send(p NotificationPayload) error {
 	// 	return fmt.Errorf("channel send error: %w", err)
 	// }
 
-	// 2. 本人にDM送信 (IDがある場合のみ)
-	if p.SlackUserID != "" {
-		_, _, err := s.client.PostMessage(
-			p.SlackUserID,
-			slack.MsgOptionBlocks(blocks...),
-		)
+	options := []slack.MsgOption{slack.MsgOptionBlocks(blocks...)}
+	if p.Text != "" {
+		options = append(options, slack.MsgOptionText(p.Text, false)) // 通知プレビュー用
+	}
+
+	// 2. チャンネル宛てのメッセージ（人員アラートなど）
+	if p.ChannelID != "" {
+		ts, attempts, err := s.postMessage(ctx, p.ChannelID, options...)
 		if err != nil {
-			// DM失敗はログだけ出してエラーにはしない（チャンネルには届いているため）
-			log.Printf("dm send error for user %s: %v", p.UserName, err)
+			return "", attempts, fmt.Errorf("channel send error: %w", err)
 		}
+		return ts, attempts, nil
+	}
+
+	// 3. 本人にDM送信
+	if p.SlackUserID == "" {
+		return "", 0, fmt.Errorf("slack user id is not registered for %s", p.UserName)
+	}
+	ts, attempts, err := s.postMessage(
+		ctx,
+		p.SlackUserID,
+		options...,
+	)
+	if err != nil {
+		return "", attempts, fmt.Errorf("dm send error for user %s: %w", p.UserName, err)
 	}
 
-	return nil
+	return ts, attempts, nil
 }
 
 // buildMessageBlocks リッチなメッセージを作成
@@ -100,22 +353,7 @@ func (s *SlackService) buildMessageBlocks(p NotificationPayload) []slack.Block {
 	timeStr := s.timeIDToTimeString(p.TimeID)
 
 	// アクションごとの色とタイトル設定
-	var title, emoji string
-	// SlackのBlockKitでは直接色は指定できないが、Attachmentを使うか、絵文字で表現する
-	switch p.ActionType {
-	case "CREATE":
-		title = "シフト追加"
-		emoji = ":sparkles:" // キラキラ
-	case "UPDATE":
-		title = "シフト変更"
-		emoji = ":pencil2:" // 鉛筆
-	case "DELETE":
-		title = "シフト削除"
-		emoji = ":wastebasket:" // ゴミ箱
-	default:
-		title = "お知らせ"
-		emoji = ":mega:"
-	}
+	title, emoji := actionLabel(p.ActionType)
 
 	headerText := fmt.Sprintf("%s %s通知", emoji, title)
 	headerBlock := slack.NewHeaderBlock(slack.NewTextBlockObject("plain_text", headerText, false, false))
@@ -125,11 +363,15 @@ func (s *SlackService) buildMessageBlocks(p NotificationPayload) []slack.Block {
 		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*ユーザー:*\n%s", p.UserName), false, false),
 		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*日付:*\n%s", p.Date), false, false),
 		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*時刻:*\n%s", timeStr), false, false),
-		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*天気:*\n%s", p.Weather), false, false),
+		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*天気:*\n%s", p.weatherText("~%s~ → *%s*")), false, false),
 	}
 
-	// 差分情報
-	if p.ActionType == "UPDATE" {
+	// 差分情報（天気だけが変わった場合はタスクをそのまま載せる）
+	if p.ActionType == "UPDATE" && p.OldTaskName == p.TaskName {
+		fields = append(fields,
+			slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*タスク:*\n%s", p.TaskName), false, false),
+		)
+	} else if p.ActionType == "UPDATE" {
 		fields = append(fields,
 			slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*変更前:*\n~%s~", p.OldTaskName), false, false),
 			slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*変更後:*\n*%s*", p.TaskName), false, false),
@@ -150,10 +392,27 @@ func (s *SlackService) buildMessageBlocks(p NotificationPayload) []slack.Block {
 	return []slack.Block{headerBlock, sectionBlock, dividerBlock}
 }
 
+// actionLabel アクションごとのタイトルと絵文字
+// SlackのBlockKitでは直接色は指定できないが、Attachmentを使うか、絵文字で表現する
+func actionLabel(actionType string) (title, emoji string) {
+	switch actionType {
+	case "CREATE":
+		return "シフト追加", ":sparkles:" // キラキラ
+	case "UPDATE":
+		return "シフト変更", ":pencil2:" // 鉛筆
+	case "DELETE":
+		return "シフト削除", ":wastebasket:" // ゴミ箱
+	default:
+		return "お知らせ", ":mega:"
+	}
+}
+
+// actionTitle アクションのタイトルのみ（メール件名など絵文字を使わない場所向け）
+func actionTitle(actionType string) string {
+	title, _ := actionLabel(actionType)
+	return title
+}
+
 func (s *SlackService) timeIDToTimeString(timeID int) string {
-	hoursFromBase := (timeID - BaseTimeID) / 2
-	minutesFromBase := ((timeID - BaseTimeID) % 2) * 30
-	hours := 6 + hoursFromBase
-	minutes := minutesFromBase
-	return fmt.Sprintf("%02d:%02d", hours, minutes)
+	return timeIDToString(timeID)
 }
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"sort"
+
+	"seeft-slack-notification/internal/model"
+)
+
+// SlotKey シフト枠を一意に表すキー（ユーザーを含まない）
+type SlotKey struct {
+	YearID  int
+	Date    string
+	Weather string
+	TimeID  int
+}
+
+// ShiftKey ユーザーの1つのシフトを表すキー
+// 同期と同じく年度・日付・時間帯・ユーザーで決まり、天気はシフトの属性なので含めない
+type ShiftKey struct {
+	UserID int
+	YearID int
+	Date   string
+	TimeID int
+}
+
+// TaskSlotKey タスクごとのシフト枠
+type TaskSlotKey struct {
+	TaskName string
+	SlotKey
+}
+
+// RequirementKey 必要人数を定める単位
+type RequirementKey struct {
+	YearID   int
+	TaskName string
+	Date     string
+	Weather  string
+	TimeID   int
+}
+
+// StaffingChange 同期で起きた、あるタスク・枠への1人分の増減
+type StaffingChange struct {
+	TaskSlotKey
+	UserID   int
+	UserName string
+	Delta    int // +1: 配置された, -1: 外れた
+}
+
+// StaffingTracker 同期処理中の人員の増減を記録し、枠ごとの人数を前後で比較する
+type StaffingTracker struct {
+	before  map[TaskSlotKey]int
+	delta   map[TaskSlotKey]int
+	changes []StaffingChange
+}
+
+// isStaffedTask 人数を数える対象のタスクか（空欄やNGは配置ではない）
+func isStaffedTask(taskName string) bool {
+	return taskName != "" && taskName != model.NGTaskName
+}
+
+// slotKeyOf シフトから枠のキーを作る
+func slotKeyOf(shift *model.Shift) SlotKey {
+	return SlotKey{
+		YearID:  shift.YearID,
+		Date:    shift.Date,
+		Weather: shift.Weather,
+		TimeID:  shift.TimeID,
+	}
+}
+
+// NewStaffingTracker 同期前の全シフトから、タスク・枠ごとの人数を数えておく
+func NewStaffingTracker(shifts []*model.Shift) *StaffingTracker {
+	t := &StaffingTracker{
+		before: make(map[TaskSlotKey]int),
+		delta:  make(map[TaskSlotKey]int),
+	}
+	for _, shift := range shifts {
+		if isStaffedTask(shift.TaskName) {
+			t.before[TaskSlotKey{TaskName: shift.TaskName, SlotKey: slotKeyOf(shift)}]++
+		}
+	}
+	return t
+}
+
+// Record あるシフトで taskName への配置が delta 人分増減したことを記録する
+func (t *StaffingTracker) Record(taskName string, shift *model.Shift, user *model.User, delta int) {
+	if !isStaffedTask(taskName) {
+		return
+	}
+
+	key := TaskSlotKey{TaskName: taskName, SlotKey: slotKeyOf(shift)}
+	t.delta[key] += delta
+	t.changes = append(t.changes, StaffingChange{
+		TaskSlotKey: key,
+		UserID:      user.ID,
+		UserName:    user.Name,
+		Delta:       delta,
+	})
+}
+
+// RecordTransition 変更前後のシフトから増減を記録する（新規・削除はどちらかがnil）
+func (t *StaffingTracker) RecordTransition(oldShift, newShift *model.Shift, user *model.User) {
+	if oldShift != nil {
+		t.Record(oldShift.TaskName, oldShift, user, -1)
+	}
+	if newShift != nil {
+		t.Record(newShift.TaskName, newShift, user, +1)
+	}
+}
+
+// Headcount 枠の同期前・同期後の人数
+func (t *StaffingTracker) Headcount(key TaskSlotKey) (before, after int) {
+	before = t.before[key]
+	return before, before + t.delta[key]
+}
+
+// Changes 記録した増減の一覧
+func (t *StaffingTracker) Changes() []StaffingChange {
+	return t.changes
+}
+
+// sortTaskSlotKeys タスク名 → 年度 → 日付 → 天気 → 時刻 の順に並べる
+func sortTaskSlotKeys(keys []TaskSlotKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.TaskName != b.TaskName {
+			return a.TaskName < b.TaskName
+		}
+		if a.YearID != b.YearID {
+			return a.YearID < b.YearID
+		}
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Weather != b.Weather {
+			return a.Weather < b.Weather
+		}
+		return a.TimeID < b.TimeID
+	})
+}
+
+// RequirementHeadcounts 人数が変わった枠の同期前・同期後の人数
+func (t *StaffingTracker) RequirementHeadcounts() map[RequirementKey][2]int {
+	changed := make(map[RequirementKey]bool)
+	for key, d := range t.delta {
+		if d != 0 {
+			changed[requirementKeyOf(key)] = true
+		}
+	}
+
+	counts := make(map[RequirementKey][2]int)
+	for key, n := range t.before {
+		if rk := requirementKeyOf(key); changed[rk] {
+			c := counts[rk]
+			c[0] += n
+			c[1] += n
+			counts[rk] = c
+		}
+	}
+	for key, d := range t.delta {
+		if rk := requirementKeyOf(key); changed[rk] {
+			c := counts[rk]
+			c[1] += d
+			counts[rk] = c
+		}
+	}
+	return counts
+}
+
+func requirementKeyOf(key TaskSlotKey) RequirementKey {
+	return RequirementKey{
+		YearID:   key.YearID,
+		TaskName: key.TaskName,
+		Date:     key.Date,
+		Weather:  key.Weather,
+		TimeID:   key.TimeID,
+	}
+}
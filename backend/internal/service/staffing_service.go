@@ -0,0 +1,211 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"seeft-slack-notification/internal/config"
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/repository"
+)
+
+// StaffingService 必要人数の管理と、同期後の人員不足アラートを行う
+type StaffingService struct {
+	db              *sql.DB
+	requirementRepo *repository.StaffingRequirementRepository
+	slackService    *SlackService
+	deliveryService *DeliveryService
+	calendar        *ShiftCalendar
+	alertChannelID  string
+}
+
+func NewStaffingService(
+	cfg *config.Config,
+	db *sql.DB,
+	requirementRepo *repository.StaffingRequirementRepository,
+	slackService *SlackService,
+	deliveryService *DeliveryService,
+	calendar *ShiftCalendar,
+) *StaffingService {
+	return &StaffingService{
+		db:              db,
+		requirementRepo: requirementRepo,
+		slackService:    slackService,
+		deliveryService: deliveryService,
+		calendar:        calendar,
+		alertChannelID:  cfg.StaffingAlertChannelID,
+	}
+}
+
+// ListRequirements 登録済みの必要人数一覧（lead には担当タスクの分だけ返す）
+func (s *StaffingService) ListRequirements(actor *model.Principal) ([]*model.StaffingRequirement, error) {
+	reqs, err := s.requirementRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]*model.StaffingRequirement, 0, len(reqs))
+	for _, r := range reqs {
+		if actor.CanManageTask(r.TaskName) {
+			visible = append(visible, r)
+		}
+	}
+	return visible, nil
+}
+
+// ReplaceRequirements 必要人数をリクエストの内容で全て置き換える
+func (s *StaffingService) ReplaceRequirements(items []model.StaffingRequirementItem) error {
+	for _, item := range items {
+		if item.YearID <= 0 || strings.TrimSpace(item.TaskName) == "" || item.Date == "" || item.Weather == "" {
+			return fmt.Errorf("%w: yearID, taskName, date and weather are required", ErrInvalidInput)
+		}
+		if item.MinStaff < 0 {
+			return fmt.Errorf("%w: minStaff must not be negative", ErrInvalidInput)
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.requirementRepo.ReplaceAll(tx, items); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Coverage 必要人数ごとの現在の配置状況（understaffedOnly なら不足している枠のみ、lead には担当タスクのみ）
+func (s *StaffingService) Coverage(actor *model.Principal, understaffedOnly bool) ([]*model.StaffingCoverage, error) {
+	coverages, err := s.requirementRepo.GetCoverage()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.StaffingCoverage, 0, len(coverages))
+	for _, c := range coverages {
+		if !actor.CanManageTask(c.TaskName) {
+			continue
+		}
+		c.Time = timeIDToString(c.TimeID)
+		if c.Assigned < c.MinStaff {
+			c.Shortage = c.MinStaff - c.Assigned
+		}
+		if understaffedOnly && c.Shortage == 0 {
+			continue
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// staffingAlert 同期で必要人数を割った・満たした枠
+type staffingAlert struct {
+	key      RequirementKey
+	minStaff int
+	before   int
+	after    int
+}
+
+// CheckAfterSync 同期で人数が変わった枠を必要人数と比較し、
+// 新たに不足した枠と不足が解消した枠をアラート用チャンネルに通知する
+func (s *StaffingService) CheckAfterSync(syncID int, tracker *StaffingTracker) {
+	headcounts := tracker.RequirementHeadcounts()
+	if len(headcounts) == 0 {
+		return
+	}
+
+	reqs, err := s.requirementRepo.GetAll()
+	if err != nil {
+		log.Printf("Failed to load staffing requirements: %v", err)
+		return
+	}
+
+	var understaffed, fixed []staffingAlert
+	for _, req := range reqs {
+		key := RequirementKey{YearID: req.YearID, TaskName: req.TaskName, Date: req.Date, Weather: req.Weather, TimeID: req.TimeID}
+		counts, ok := headcounts[key]
+		if !ok {
+			continue
+		}
+
+		alert := staffingAlert{key: key, minStaff: req.MinStaff, before: counts[0], after: counts[1]}
+		switch {
+		case alert.before >= req.MinStaff && alert.after < req.MinStaff:
+			understaffed = append(understaffed, alert)
+		case alert.before < req.MinStaff && alert.after >= req.MinStaff:
+			fixed = append(fixed, alert)
+		}
+	}
+
+	if len(understaffed) == 0 && len(fixed) == 0 {
+		return
+	}
+
+	s.sortAlerts(understaffed)
+	s.sortAlerts(fixed)
+
+	var lines []string
+	if len(understaffed) > 0 {
+		lines = append(lines, fmt.Sprintf("*:rotating_light: 人員不足になった枠 (%d件)*", len(understaffed)))
+		for _, a := range understaffed {
+			lines = append(lines, formatStaffingAlert(a))
+		}
+	}
+	if len(fixed) > 0 {
+		lines = append(lines, fmt.Sprintf("*:white_check_mark: 不足が解消した枠 (%d件)*", len(fixed)))
+		for _, a := range fixed {
+			lines = append(lines, formatStaffingAlert(a))
+		}
+	}
+
+	title := fmt.Sprintf("人員アラート: 不足 %d件 / 解消 %d件", len(understaffed), len(fixed))
+	onResult := s.deliveryService.Track(&model.NotificationDelivery{
+		SyncID:    &syncID,
+		Kind:      "STAFFING_ALERT",
+		Channel:   model.DeliveryRouteChannel,
+		Recipient: s.alertChannelID,
+		Status:    model.DeliveryStatusQueued,
+	})
+	s.slackService.EnqueueNotification(NotificationPayload{
+		ActionType: "STAFFING_ALERT",
+		ChannelID:  s.alertChannelID,
+		Text:       title,
+		Blocks:     buildDigestBlocks(title, lines),
+		OnResult:   onResult,
+	})
+}
+
+// sortAlerts 年度 → 日付（開催日程順） → 時刻 → タスク名 の順に並べる
+func (s *StaffingService) sortAlerts(alerts []staffingAlert) {
+	sort.Slice(alerts, func(i, j int) bool {
+		a, b := alerts[i].key, alerts[j].key
+		if a.YearID != b.YearID {
+			return a.YearID < b.YearID
+		}
+		if c := s.calendar.CompareDates(a.Date, b.Date); c != 0 {
+			return c < 0
+		}
+		if a.TimeID != b.TimeID {
+			return a.TimeID < b.TimeID
+		}
+		if a.Weather != b.Weather {
+			return a.Weather < b.Weather
+		}
+		return a.TaskName < b.TaskName
+	})
+}
+
+// formatStaffingAlert "• 救護 1日目 10:00 (晴れ) 3人 → 1人 / 必要 2人"
+func formatStaffingAlert(a staffingAlert) string {
+	return fmt.Sprintf("• %s %s %s (%s) %d人 → %d人 / 必要 %d人",
+		a.key.TaskName, a.key.Date, timeIDToString(a.key.TimeID), a.key.Weather, a.before, a.after, a.minStaff)
+}
@@ -0,0 +1,582 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/repository"
+)
+
+// SwapService シフト交換の申請・承諾・承認と、承認された交換の反映を行う
+// 交換は申請者のシフトと相手のシフトのタスクを入れ替える
+// 同じ枠どうしならタスク名を入れ替え、別の枠どうしなら互いの枠に移る
+type SwapService struct {
+	db            *sql.DB
+	swapRepo      *repository.ShiftSwapRepository
+	shiftRepo     *repository.ShiftRepository
+	userRepo      *repository.UserRepository
+	actionLogRepo *repository.ActionLogRepository
+	shiftReadRepo *repository.ShiftReadRepository
+	syncRunRepo   *repository.SyncRunRepository
+	leadService   *TaskLeadService
+	notifier      *directNotifier
+	broker        *ChangeBroker
+}
+
+func NewSwapService(
+	db *sql.DB,
+	swapRepo *repository.ShiftSwapRepository,
+	shiftRepo *repository.ShiftRepository,
+	userRepo *repository.UserRepository,
+	actionLogRepo *repository.ActionLogRepository,
+	shiftReadRepo *repository.ShiftReadRepository,
+	syncRunRepo *repository.SyncRunRepository,
+	leadService *TaskLeadService,
+	prefService *PreferenceService,
+	slackService *SlackService,
+	emailService *EmailService,
+	deliveryService *DeliveryService,
+	broker *ChangeBroker,
+) *SwapService {
+	return &SwapService{
+		db:            db,
+		swapRepo:      swapRepo,
+		shiftRepo:     shiftRepo,
+		userRepo:      userRepo,
+		actionLogRepo: actionLogRepo,
+		shiftReadRepo: shiftReadRepo,
+		syncRunRepo:   syncRunRepo,
+		leadService:   leadService,
+		notifier:      newDirectNotifier(prefService, slackService, emailService, deliveryService),
+		broker:        broker,
+	}
+}
+
+// Create 自分のシフトと相手のシフトの交換を申請し、相手に知らせる
+func (s *SwapService) Create(actor *model.Principal, req model.ShiftSwapRequest) (*model.ShiftSwap, error) {
+	if req.ShiftID <= 0 || req.CounterpartShiftID <= 0 {
+		return nil, fmt.Errorf("%w: shift_id and counterpart_shift_id are required", ErrInvalidInput)
+	}
+
+	mine, err := s.activeShift(req.ShiftID)
+	if err != nil {
+		return nil, err
+	}
+	if mine.UserID != actor.UserID {
+		return nil, ErrNotShiftOwner
+	}
+
+	theirs, err := s.activeShift(req.CounterpartShiftID)
+	if err != nil {
+		return nil, err
+	}
+	if theirs.UserID == actor.UserID {
+		return nil, fmt.Errorf("%w: cannot swap with yourself", ErrInvalidInput)
+	}
+	if sameSlot(mine, theirs) && mine.TaskName == theirs.TaskName {
+		return nil, fmt.Errorf("%w: both shifts have the same task", ErrInvalidInput)
+	}
+	if !sameSlot(mine, theirs) {
+		// 別の枠どうしの交換では、互いに相手の枠が空いている必要がある
+		if err := s.checkSlotFree(s.db, theirs.UserID, mine); err != nil {
+			return nil, err
+		}
+		if err := s.checkSlotFree(s.db, mine.UserID, theirs); err != nil {
+			return nil, err
+		}
+	}
+
+	open, err := s.swapRepo.HasOpen([]int{mine.ID, theirs.ID})
+	if err != nil {
+		return nil, err
+	}
+	if open {
+		return nil, fmt.Errorf("%w: shift already has an open swap request", ErrConflict)
+	}
+
+	swap := &model.ShiftSwap{
+		Message:     strings.TrimSpace(req.Message),
+		Requester:   model.SwapSide{UserID: mine.UserID, ShiftID: mine.ID, TaskName: mine.TaskName},
+		Counterpart: model.SwapSide{UserID: theirs.UserID, ShiftID: theirs.ID, TaskName: theirs.TaskName},
+	}
+	if err := s.swapRepo.Create(swap); err != nil {
+		return nil, err
+	}
+
+	created, err := s.swapRepo.GetByID(swap.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyUsers(created, "SWAP_REQUESTED", "シフト交換の申請が届きました", created.Counterpart.UserID)
+	return created, nil
+}
+
+// List 自分が申請した・申請された交換の一覧（status で絞り込める）
+func (s *SwapService) List(actor *model.Principal, status string) ([]*model.ShiftSwap, error) {
+	if status != "" && !model.ValidSwapStatus(status) {
+		return nil, fmt.Errorf("%w: invalid status: %q", ErrInvalidInput, status)
+	}
+	return s.swapRepo.List(model.ShiftSwapFilter{UserID: actor.UserID, Status: status})
+}
+
+// PendingApprovals 相手が承諾し、リーダーの承認を待っている交換（自分がまだ承認していないタスクがあるものだけ）
+func (s *SwapService) PendingApprovals(actor *model.Principal) ([]*model.ShiftSwap, error) {
+	swaps, err := s.swapRepo.List(model.ShiftSwapFilter{Status: model.SwapStatusAccepted})
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]*model.ShiftSwap, 0, len(swaps))
+	for _, w := range swaps {
+		if len(approvableTasks(actor, w)) > 0 {
+			visible = append(visible, w)
+		}
+	}
+	return visible, nil
+}
+
+// Accept 相手が交換を承諾する。申請者と、担当タスクのリーダーに知らせる
+func (s *SwapService) Accept(actor *model.Principal, id int) (*model.ShiftSwap, error) {
+	w, err := s.transitionByCounterpart(actor, id, model.SwapStatusAccepted)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyUsers(w, "SWAP_ACCEPTED", "シフト交換が承諾されました（リーダーの承認待ち）", w.Requester.UserID)
+	s.notifyLeads(w)
+	return w, nil
+}
+
+// Decline 相手が交換を断る。申請者に知らせる
+func (s *SwapService) Decline(actor *model.Principal, id int) (*model.ShiftSwap, error) {
+	w, err := s.transitionByCounterpart(actor, id, model.SwapStatusDeclined)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyUsers(w, "SWAP_DECLINED", "シフト交換が断られました", w.Requester.UserID)
+	return w, nil
+}
+
+// Cancel 申請者が交換を取り下げる（承認・却下の前まで）。相手に知らせる
+func (s *SwapService) Cancel(actor *model.Principal, id int) (*model.ShiftSwap, error) {
+	w, err := s.swapRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if w.Requester.UserID != actor.UserID {
+		return nil, fmt.Errorf("%w: only the requester can cancel", ErrForbidden)
+	}
+	if w.Status != model.SwapStatusPending && w.Status != model.SwapStatusAccepted {
+		return nil, fmt.Errorf("%w: shift swap is already %s", ErrConflict, w.Status)
+	}
+
+	if err := s.swapRepo.Transition(s.db, id, w.Status, model.SwapStatusCancelled, nil); err != nil {
+		return nil, err
+	}
+	w.Status = model.SwapStatusCancelled
+
+	s.notifyUsers(w, "SWAP_CANCELLED", "シフト交換が取り下げられました", w.Counterpart.UserID)
+	return w, nil
+}
+
+// Approve リーダーが担当するタスクについて交換を承認する
+// 交換する全てのタスク（タスクが違えば両方）の承認が揃ったら shifts に反映し、当事者の2人に知らせる
+// 反映は変更履歴 (action_log) に残し、GASがシートに書き戻せるように書き戻し待ちにする
+func (s *SwapService) Approve(actor *model.Principal, id int) (*model.ShiftSwap, error) {
+	w, err := s.swapRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !canManageAnySwapTask(actor, w) {
+		return nil, fmt.Errorf("%w: not a lead of %s", ErrForbidden, strings.Join(swapTasks(w), " / "))
+	}
+	if w.Status != model.SwapStatusAccepted {
+		return nil, fmt.Errorf("%w: shift swap is %s", ErrConflict, w.Status)
+	}
+	tasks := approvableTasks(actor, w)
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("%w: already approved by you", ErrConflict)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	approved, err := s.swapRepo.AddApprovals(tx, id, tasks)
+	if err != nil {
+		return nil, err
+	}
+	w.ApprovedTasks = approved
+	w.ApproverID = &actor.UserID
+
+	if !fullyApproved(w) {
+		// もう一方のタスクのリーダーの承認を待つ
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return w, nil
+	}
+
+	if err := s.swapRepo.Transition(tx, id, model.SwapStatusAccepted, model.SwapStatusApproved, &actor.UserID); err != nil {
+		return nil, err
+	}
+
+	syncID, err := s.apply(tx, w)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.swapRepo.SetSyncID(tx, id, syncID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	w.Status = model.SwapStatusApproved
+	w.SyncID = &syncID
+
+	// アプリで開いている画面(SSE)に新しい変更を知らせる
+	s.broker.Notify(w.Requester.UserID)
+	s.broker.Notify(w.Counterpart.UserID)
+
+	s.notifyUsers(w, "SWAP_APPROVED", "シフト交換が承認されました", w.Requester.UserID, w.Counterpart.UserID)
+	return w, nil
+}
+
+// Reject リーダーが交換を却下する（どちらかのタスクのリーダーなら却下できる）。当事者の2人に知らせる
+func (s *SwapService) Reject(actor *model.Principal, id int) (*model.ShiftSwap, error) {
+	w, err := s.swapRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !canManageAnySwapTask(actor, w) {
+		return nil, fmt.Errorf("%w: not a lead of %s", ErrForbidden, strings.Join(swapTasks(w), " / "))
+	}
+
+	if err := s.swapRepo.Transition(s.db, id, model.SwapStatusAccepted, model.SwapStatusRejected, &actor.UserID); err != nil {
+		return nil, err
+	}
+	w.Status = model.SwapStatusRejected
+	w.ApproverID = &actor.UserID
+
+	s.notifyUsers(w, "SWAP_REJECTED", "シフト交換が却下されました", w.Requester.UserID, w.Counterpart.UserID)
+	return w, nil
+}
+
+// Exports 承認済みでシートに書き戻していない交換と、書き戻すセルの変更（GAS向け）
+func (s *SwapService) Exports() ([]*model.ShiftSwapExport, error) {
+	swaps, err := s.swapRepo.GetUnexported()
+	if err != nil {
+		return nil, err
+	}
+
+	exports := make([]*model.ShiftSwapExport, 0, len(swaps))
+	for _, w := range swaps {
+		exports = append(exports, &model.ShiftSwapExport{SwapID: w.ID, Changes: swapSheetChanges(w)})
+	}
+	return exports, nil
+}
+
+// AckExports シートへの書き戻しが済んだ交換に印を付ける（GAS向け）
+func (s *SwapService) AckExports(ids []int) (int, error) {
+	if len(ids) == 0 {
+		return 0, fmt.Errorf("%w: swap_ids is required", ErrInvalidInput)
+	}
+	return s.swapRepo.MarkExported(ids)
+}
+
+// --- 以下、ヘルパー関数 ---
+
+// activeShift 有効なシフトを取得（存在しない・削除済みなら ErrShiftNotFound）
+func (s *SwapService) activeShift(id int) (*model.Shift, error) {
+	shift, err := s.shiftRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if shift == nil || shift.DeletedAt != nil {
+		return nil, fmt.Errorf("%w: id=%d", ErrShiftNotFound, id)
+	}
+	return shift, nil
+}
+
+// checkSlotFree ユーザーが shift と同じ枠に別のシフトを持っていないか
+func (s *SwapService) checkSlotFree(q repository.DBTX, userID int, shift *model.Shift) error {
+	busy, err := s.shiftRepo.ExistsActive(q, shift.YearID, shift.TimeID, shift.Date, shift.Weather, userID)
+	if err != nil {
+		return err
+	}
+	if busy {
+		return fmt.Errorf("%w: user %d already has a shift at %s %s (%s)",
+			ErrConflict, userID, shift.Date, timeIDToString(shift.TimeID), shift.Weather)
+	}
+	return nil
+}
+
+// transitionByCounterpart 交換の相手として、承諾待ち (pending) の申請の状態を変える
+func (s *SwapService) transitionByCounterpart(actor *model.Principal, id int, to string) (*model.ShiftSwap, error) {
+	w, err := s.swapRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if w.Counterpart.UserID != actor.UserID {
+		return nil, fmt.Errorf("%w: only the counterpart can respond", ErrForbidden)
+	}
+
+	if err := s.swapRepo.Transition(s.db, id, model.SwapStatusPending, to, nil); err != nil {
+		return nil, err
+	}
+	w.Status = to
+	return w, nil
+}
+
+// canManageAnySwapTask 交換するタスクのどちらかを担当しているか（admin は全て）
+func canManageAnySwapTask(actor *model.Principal, w *model.ShiftSwap) bool {
+	for _, task := range swapTasks(w) {
+		if actor.CanManageTask(task) {
+			return true
+		}
+	}
+	return false
+}
+
+// approvableTasks 交換するタスクのうち、actor が担当していてまだ承認されていないもの
+// admin は残りの全てのタスクを一度に承認できる
+func approvableTasks(actor *model.Principal, w *model.ShiftSwap) []string {
+	var tasks []string
+	for _, task := range swapTasks(w) {
+		if actor.CanManageTask(task) && !slices.Contains(w.ApprovedTasks, task) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks
+}
+
+// fullyApproved 交換する全てのタスクの承認が揃ったか
+func fullyApproved(w *model.ShiftSwap) bool {
+	for _, task := range swapTasks(w) {
+		if !slices.Contains(w.ApprovedTasks, task) {
+			return false
+		}
+	}
+	return true
+}
+
+// swapTasks 交換に関わるタスク名（重複なし）
+func swapTasks(w *model.ShiftSwap) []string {
+	if w.Requester.TaskName == w.Counterpart.TaskName {
+		return []string{w.Requester.TaskName}
+	}
+	return []string{w.Requester.TaskName, w.Counterpart.TaskName}
+}
+
+// apply 交換を shifts に反映し、変更履歴を残す。変更履歴のまとまり(sync run)のIDを返す
+// 申請後にどちらかのシフトが変わっていたら ErrConflict
+func (s *SwapService) apply(tx *sql.Tx, w *model.ShiftSwap) (int, error) {
+	mine, err := s.lockSwapShift(tx, w.Requester)
+	if err != nil {
+		return 0, err
+	}
+	theirs, err := s.lockSwapShift(tx, w.Counterpart)
+	if err != nil {
+		return 0, err
+	}
+
+	syncID, err := s.syncRunRepo.Create(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	var changes []swapChange
+	if sameSlot(mine, theirs) {
+		// 同じ枠どうしはタスク名を入れ替える
+		changes = []swapChange{
+			{shift: mine, actionType: "UPDATE", oldTask: mine.TaskName, newTask: theirs.TaskName},
+			{shift: theirs, actionType: "UPDATE", oldTask: theirs.TaskName, newTask: mine.TaskName},
+		}
+	} else {
+		// 別の枠どうしは、元の枠から外れて相手の枠に入る
+		if err := s.checkSlotFree(tx, theirs.UserID, mine); err != nil {
+			return 0, err
+		}
+		if err := s.checkSlotFree(tx, mine.UserID, theirs); err != nil {
+			return 0, err
+		}
+		changes = []swapChange{
+			{shift: mine, actionType: "DELETE", oldTask: mine.TaskName},
+			{shift: theirs, actionType: "DELETE", oldTask: theirs.TaskName},
+			{shift: movedShift(mine, theirs.UserID), actionType: "CREATE", newTask: mine.TaskName},
+			{shift: movedShift(theirs, mine.UserID), actionType: "CREATE", newTask: theirs.TaskName},
+		}
+	}
+
+	for _, c := range changes {
+		if err := s.applyChange(tx, syncID, c); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := s.syncRunRepo.Finish(tx, syncID, len(changes)); err != nil {
+		return 0, err
+	}
+	return syncID, nil
+}
+
+// swapChange 交換で起きるシフト1件分の変更
+type swapChange struct {
+	shift      *model.Shift
+	actionType string // "CREATE", "UPDATE", "DELETE"
+	oldTask    string
+	newTask    string
+}
+
+// applyChange 1件分の変更を shifts に書き、変更履歴を残して未読に戻す
+// 変更履歴の形は同期(SyncShifts)のものと揃える
+func (s *SwapService) applyChange(tx *sql.Tx, syncID int, c swapChange) error {
+	diff := map[string]interface{}{}
+
+	switch c.actionType {
+	case "UPDATE":
+		c.shift.TaskName = c.newTask
+		if err := s.shiftRepo.Update(tx, c.shift); err != nil {
+			return err
+		}
+		diff["changes"] = []map[string]string{
+			{"field": "task_name", "old": c.oldTask, "new": c.newTask},
+		}
+	case "CREATE":
+		if err := s.shiftRepo.Assign(tx, c.shift); err != nil {
+			return err
+		}
+		diff["new_task"] = c.newTask
+	case "DELETE":
+		if err := s.shiftRepo.Delete(tx, c.shift.ID); err != nil {
+			return err
+		}
+		diff["deleted_task"] = c.oldTask
+	}
+
+	payloadJSON, err := json.Marshal(diff)
+	if err != nil {
+		return fmt.Errorf("failed to marshal diff: %w", err)
+	}
+	if _, err := s.actionLogRepo.Create(tx, syncID, c.shift.ID, c.actionType, payloadJSON); err != nil {
+		return err
+	}
+
+	return s.shiftReadRepo.Upsert(tx, c.shift.ID, c.shift.UserID, false)
+}
+
+// lockSwapShift 交換するシフトをロックし、申請時から変わっていないか確かめる
+func (s *SwapService) lockSwapShift(tx *sql.Tx, side model.SwapSide) (*model.Shift, error) {
+	shift, err := s.shiftRepo.LockByID(tx, side.ShiftID)
+	if err != nil {
+		return nil, err
+	}
+	if shift == nil || shift.UserID != side.UserID || shift.TaskName != side.TaskName {
+		return nil, fmt.Errorf("%w: shift %d has changed since the swap was requested", ErrConflict, side.ShiftID)
+	}
+	return shift, nil
+}
+
+// movedShift shift と同じ枠・タスクで、担当を userID にしたもの
+func movedShift(shift *model.Shift, userID int) *model.Shift {
+	return &model.Shift{
+		YearID:   shift.YearID,
+		TimeID:   shift.TimeID,
+		Date:     shift.Date,
+		Weather:  shift.Weather,
+		UserID:   userID,
+		TaskName: shift.TaskName,
+	}
+}
+
+// sameSlot 2つのシフトが同じ枠（年度・時間・日付・天気）か
+func sameSlot(a, b *model.Shift) bool {
+	return a.YearID == b.YearID && a.TimeID == b.TimeID && a.Date == b.Date && a.Weather == b.Weather
+}
+
+// swapSheetChanges 承認済みの交換をシートのセルの変更に直す（GASの同期と同じ形）
+func swapSheetChanges(w *model.ShiftSwap) []model.ShiftChange {
+	r, c := w.Requester, w.Counterpart
+	cell := func(slot model.SwapSide, userName, taskName string) model.ShiftChange {
+		return model.ShiftChange{
+			YearID:   slot.YearID,
+			TimeID:   slot.TimeID,
+			Date:     slot.Date,
+			Weather:  slot.Weather,
+			UserName: userName,
+			TaskName: taskName,
+		}
+	}
+
+	if r.YearID == c.YearID && r.TimeID == c.TimeID && r.Date == c.Date && r.Weather == c.Weather {
+		return []model.ShiftChange{
+			cell(r, r.UserName, c.TaskName),
+			cell(c, c.UserName, r.TaskName),
+		}
+	}
+	return []model.ShiftChange{
+		cell(r, r.UserName, ""),
+		cell(r, c.UserName, r.TaskName),
+		cell(c, c.UserName, ""),
+		cell(c, r.UserName, c.TaskName),
+	}
+}
+
+// swapLines 通知に載せる交換の内容 "• 山田: 1日目 10:00 (晴れ) 受付 ⇄ 佐藤: ..."
+func swapLines(w *model.ShiftSwap) []string {
+	side := func(s model.SwapSide) string {
+		return fmt.Sprintf("%s: %s %s (%s) %s", s.UserName, s.Date, timeIDToString(s.TimeID), s.Weather, s.TaskName)
+	}
+
+	lines := []string{"• " + side(w.Requester), "⇄ " + side(w.Counterpart)}
+	if w.Message != "" {
+		lines = append(lines, "> "+w.Message)
+	}
+	return lines
+}
+
+// notifyLeads 交換するタスクのリーダーに承認を依頼する
+func (s *SwapService) notifyLeads(w *model.ShiftSwap) {
+	leadsByTask, err := s.leadService.LeadsByTask()
+	if err != nil {
+		log.Printf("Failed to load task leads: %v", err)
+		return
+	}
+
+	var leadIDs []int
+	seen := make(map[int]bool)
+	for _, task := range swapTasks(w) {
+		for _, id := range leadsByTask[task] {
+			if !seen[id] {
+				seen[id] = true
+				leadIDs = append(leadIDs, id)
+			}
+		}
+	}
+
+	s.notifyUsers(w, "SWAP_APPROVAL_REQUESTED", "シフト交換の承認依頼", leadIDs...)
+}
+
+// notifyUsers 交換の内容を、各ユーザーの通知設定の送り先(DM・メール)へ送る
+func (s *SwapService) notifyUsers(w *model.ShiftSwap, kind, title string, userIDs ...int) {
+	lines := swapLines(w)
+
+	for _, userID := range userIDs {
+		user, err := s.userRepo.GetByID(userID)
+		if err != nil {
+			log.Printf("Failed to load user %d for %s: %v", userID, kind, err)
+			continue
+		}
+		s.notifier.Send(nil, user, kind, ":arrows_counterclockwise:", title, lines)
+	}
+}
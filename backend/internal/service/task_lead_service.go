@@ -0,0 +1,151 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/repository"
+)
+
+// TaskLeadService タスクリーダーの管理と、担当タスクの人員変更の通知を行う
+type TaskLeadService struct {
+	taskLeadRepo *repository.TaskLeadRepository
+	userRepo     *repository.UserRepository
+	notifier     *directNotifier
+}
+
+func NewTaskLeadService(
+	taskLeadRepo *repository.TaskLeadRepository,
+	userRepo *repository.UserRepository,
+	prefService *PreferenceService,
+	slackService *SlackService,
+	emailService *EmailService,
+	deliveryService *DeliveryService,
+) *TaskLeadService {
+	return &TaskLeadService{
+		taskLeadRepo: taskLeadRepo,
+		userRepo:     userRepo,
+		notifier:     newDirectNotifier(prefService, slackService, emailService, deliveryService),
+	}
+}
+
+// List 登録済みのタスクリーダー一覧（lead には担当タスクの分だけ返す）
+func (s *TaskLeadService) List(actor *model.Principal) ([]*model.TaskLead, error) {
+	leads, err := s.taskLeadRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]*model.TaskLead, 0, len(leads))
+	for _, l := range leads {
+		if actor.CanManageTask(l.TaskName) {
+			visible = append(visible, l)
+		}
+	}
+	return visible, nil
+}
+
+// Add タスクリーダーを登録する（lead は担当タスクにのみ追加できる）
+func (s *TaskLeadService) Add(actor *model.Principal, req model.TaskLeadRequest) error {
+	if strings.TrimSpace(req.TaskName) == "" {
+		return fmt.Errorf("%w: task_name is required", ErrInvalidInput)
+	}
+	if !actor.CanManageTask(req.TaskName) {
+		return fmt.Errorf("%w: not a lead of %s", ErrForbidden, req.TaskName)
+	}
+	if _, err := s.userRepo.GetByID(req.UserID); err != nil {
+		return err
+	}
+	return s.taskLeadRepo.Create(req.TaskName, req.UserID)
+}
+
+// Remove タスクリーダーの登録を解除する（lead は担当タスクのみ）
+func (s *TaskLeadService) Remove(actor *model.Principal, taskName string, userID int) error {
+	if !actor.CanManageTask(taskName) {
+		return fmt.Errorf("%w: not a lead of %s", ErrForbidden, taskName)
+	}
+	return s.taskLeadRepo.Delete(taskName, userID)
+}
+
+// LeadsByTask タスク名 -> リーダーのユーザーID一覧
+func (s *TaskLeadService) LeadsByTask() (map[string][]int, error) {
+	leads, err := s.taskLeadRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string][]int)
+	for _, l := range leads {
+		m[l.TaskName] = append(m[l.TaskName], l.UserID)
+	}
+	return m, nil
+}
+
+// NotifyStaffingChanges 同期で起きた人員変更を、担当タスクごとにまとめてリーダーへ送る
+// リーダー1人につき1通にまとめ、枠ごとに変更前後の人数を載せる
+func (s *TaskLeadService) NotifyStaffingChanges(syncID int, tracker *StaffingTracker, users map[int]*model.User) {
+	changes := tracker.Changes()
+	if len(changes) == 0 {
+		return
+	}
+
+	leadsByTask, err := s.LeadsByTask()
+	if err != nil {
+		log.Printf("Failed to load task leads: %v", err)
+		return
+	}
+
+	// 枠ごとに「誰が入って誰が外れたか」をまとめる
+	bySlot := make(map[TaskSlotKey][]StaffingChange)
+	for _, c := range changes {
+		bySlot[c.TaskSlotKey] = append(bySlot[c.TaskSlotKey], c)
+	}
+
+	// リーダー -> 担当タスクの変更があった枠
+	slotsByLead := make(map[int][]TaskSlotKey)
+	for key := range bySlot {
+		for _, leadID := range leadsByTask[key.TaskName] {
+			slotsByLead[leadID] = append(slotsByLead[leadID], key)
+		}
+	}
+
+	for leadID, keys := range slotsByLead {
+		lead, ok := users[leadID]
+		if !ok {
+			continue
+		}
+
+		sortTaskSlotKeys(keys)
+		lines := make([]string, 0, len(keys))
+		currentTask := ""
+		for _, key := range keys {
+			if key.TaskName != currentTask {
+				currentTask = key.TaskName
+				lines = append(lines, fmt.Sprintf("*%s*", key.TaskName))
+			}
+			lines = append(lines, formatStaffingLine(key, bySlot[key], tracker))
+		}
+
+		title := fmt.Sprintf("担当タスクの人員変更 (%d枠)", len(keys))
+		s.notifier.Send(&syncID, lead, "LEAD_SUMMARY", ":busts_in_silhouette:", title, lines)
+	}
+}
+
+// formatStaffingLine 1枠分の行 "• 1日目 10:00 (晴れ) 3人 → 4人 (+山田 / -佐藤)"
+func formatStaffingLine(key TaskSlotKey, changes []StaffingChange, tracker *StaffingTracker) string {
+	before, after := tracker.Headcount(key)
+
+	var names []string
+	for _, c := range changes {
+		if c.Delta > 0 {
+			names = append(names, "+"+c.UserName)
+		} else {
+			names = append(names, "-"+c.UserName)
+		}
+	}
+
+	return fmt.Sprintf("• %s %s (%s) %d人 → %d人 (%s)",
+		key.Date, timeIDToString(key.TimeID), key.Weather, before, after, strings.Join(names, " / "))
+}
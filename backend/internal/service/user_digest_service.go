@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/repository"
+
+	"github.com/slack-go/slack"
+)
+
+// maxDigestSectionLength Slackのsectionブロックの文字数上限(3000)に余裕を持たせた値
+const maxDigestSectionLength = 2800
+
+// UserDigestService まとめ送信(digest)を選んだユーザーに、溜まった変更を定期的に送る
+type UserDigestService struct {
+	prefRepo        *repository.NotificationPreferenceRepository
+	userRepo        *repository.UserRepository
+	actionLogRepo   *repository.ActionLogRepository
+	prefService     *PreferenceService
+	slackService    *SlackService
+	emailService    *EmailService
+	deliveryService *DeliveryService
+	interval        time.Duration
+}
+
+func NewUserDigestService(
+	prefRepo *repository.NotificationPreferenceRepository,
+	userRepo *repository.UserRepository,
+	actionLogRepo *repository.ActionLogRepository,
+	prefService *PreferenceService,
+	slackService *SlackService,
+	emailService *EmailService,
+	deliveryService *DeliveryService,
+	interval time.Duration,
+) *UserDigestService {
+	return &UserDigestService{
+		prefRepo:        prefRepo,
+		userRepo:        userRepo,
+		actionLogRepo:   actionLogRepo,
+		prefService:     prefService,
+		slackService:    slackService,
+		emailService:    emailService,
+		deliveryService: deliveryService,
+		interval:        interval,
+	}
+}
+
+// Run ctx が終了するまで interval ごとにまとめ送信を行う
+func (s *UserDigestService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.SendDigests(ctx, time.Now()); err != nil {
+				log.Printf("Failed to send user digests: %v", err)
+			}
+		}
+	}
+}
+
+// SendDigests digestユーザー全員分のまとめを送信する
+func (s *UserDigestService) SendDigests(ctx context.Context, now time.Time) error {
+	prefs, err := s.prefRepo.GetDigestUsers()
+	if err != nil {
+		return err
+	}
+
+	for _, pref := range prefs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := s.sendDigest(ctx, pref, now); err != nil {
+			// 1人の失敗で他のユーザーを止めない
+			log.Printf("Failed to send digest to user %d: %v", pref.UserID, err)
+		}
+	}
+	return nil
+}
+
+// sendDigest 1ユーザー分のまとめを作って送る
+func (s *UserDigestService) sendDigest(ctx context.Context, pref *model.NotificationPreference, now time.Time) error {
+	user, err := s.userRepo.GetByID(pref.UserID)
+	if err != nil {
+		return err
+	}
+
+	since := now.Add(-s.interval)
+	if pref.LastDigestAt != nil {
+		since = *pref.LastDigestAt
+	}
+
+	entries, err := s.actionLogRepo.GetByUserSince(user.ID, since)
+	if err != nil {
+		return err
+	}
+
+	var matched []*model.ActionLogEntry
+	var included, skipped []int // 配信記録を更新するための action_log ID
+	for _, e := range entries {
+		if !s.prefService.Matches(pref, e.ActionType, e.Date, e.TimeID, e.Weather, now) {
+			skipped = append(skipped, e.ID)
+			continue
+		}
+		matched = append(matched, e)
+	}
+
+	// 同じ枠への変更は変更前後の差分だけにまとめ、元に戻ったものは載せない
+	var lines []string
+	for _, c := range netDigestChanges(matched) {
+		if !c.changed() {
+			skipped = append(skipped, c.actionLogIDs...)
+			continue
+		}
+		included = append(included, c.actionLogIDs...)
+		lines = append(lines, c.line())
+	}
+
+	s.markDigest(user.ID, skipped, model.DeliveryStatusSuppressed, "")
+
+	if len(lines) > 0 {
+		title := fmt.Sprintf("シフト変更まとめ (%d件)", len(lines))
+		switch pref.DeliveryChannel {
+		case model.DeliveryChannelEmail:
+			s.emailService.Enqueue(EmailMessage{
+				To:      user.Email,
+				Subject: "[シフト通知] " + title,
+				Body:    strings.Join(lines, "\n") + "\n",
+				OnResult: func(r DeliveryResult) {
+					status := model.DeliveryStatusSent
+					if r.Err != nil {
+						status = model.DeliveryStatusFailed
+					}
+					s.markDigest(user.ID, included, status, "")
+				},
+			})
+		default:
+			if user.SlackUserID == "" {
+				s.markDigest(user.ID, included, model.DeliveryStatusFailed, "")
+				break
+			}
+			blocks := buildDigestBlocks(":newspaper: "+title, lines)
+			ts, err := s.slackService.PostBlocks(ctx, user.SlackUserID, title, blocks)
+			if err != nil {
+				s.markDigest(user.ID, included, model.DeliveryStatusFailed, "")
+				return err
+			}
+			s.markDigest(user.ID, included, model.DeliveryStatusSent, ts)
+		}
+	}
+
+	return s.prefRepo.MarkDigestSent(user.ID, now)
+}
+
+// markDigest まとめ送信待ちだった配信記録を更新する（失敗してもまとめ送信自体は止めない）
+func (s *UserDigestService) markDigest(userID int, actionLogIDs []int, status, slackTS string) {
+	if len(actionLogIDs) == 0 {
+		return
+	}
+	if err := s.deliveryService.MarkDigestResult(userID, actionLogIDs, status, slackTS); err != nil {
+		log.Printf("Failed to update digest deliveries for user %d: %v", userID, err)
+	}
+}
+
+// digestChange まとめ送信での1枠分の変更（期間内の最初の変更前 → 最後の変更後）
+type digestChange struct {
+	entry         *model.ActionLogEntry // 枠の情報を取るための最初の変更
+	before        string
+	after         string
+	beforeWeather string // 期間内に天気が変わった場合のみ（変わっていなければ空）
+	afterWeather  string
+	actionLogIDs  []int
+}
+
+// netDigestChanges 変更履歴（古い順）をシフトごとにまとめる。並びは各シフトの最初の変更順
+// 天気は同期で更新される値なので、同期と同じく年度・日付・時間帯で1つのシフトとして扱う
+func netDigestChanges(entries []*model.ActionLogEntry) []*digestChange {
+	byShift := make(map[ShiftKey]*digestChange)
+	var changes []*digestChange
+	for _, e := range entries {
+		oldTask, newTask := e.TaskChange()
+		key := ShiftKey{UserID: e.UserID, YearID: e.YearID, Date: e.Date, TimeID: e.TimeID}
+
+		c, ok := byShift[key]
+		if !ok {
+			c = &digestChange{entry: e, before: oldTask}
+			byShift[key] = c
+			changes = append(changes, c)
+		}
+		c.after = newTask
+		if oldWeather, newWeather, changed := e.WeatherChange(); changed {
+			// 最初に天気が変わる前の天気が、期間の始めの天気
+			if c.beforeWeather == "" {
+				c.beforeWeather = oldWeather
+			}
+			c.afterWeather = newWeather
+		}
+		c.actionLogIDs = append(c.actionLogIDs, e.ID)
+	}
+	return changes
+}
+
+// changed 期間の始めと終わりでタスクか天気が違うか（追加→削除は変更なし）
+func (c *digestChange) changed() bool {
+	if c.before != c.after {
+		return true
+	}
+	return c.after != "" && c.beforeWeather != c.afterWeather
+}
+
+// line 1枠分の変更を1行にする
+func (c *digestChange) line() string {
+	weather := c.entry.Weather
+	if c.afterWeather != "" {
+		weather = c.afterWeather
+	}
+	slot := fmt.Sprintf("%s %s (%s)", c.entry.Date, timeIDToString(c.entry.TimeID), weather)
+
+	switch {
+	case c.before == "":
+		return fmt.Sprintf("• %s 追加: %s", slot, c.after)
+	case c.after == "":
+		return fmt.Sprintf("• %s 削除: %s", slot, c.before)
+	case c.before == c.after:
+		return fmt.Sprintf("• %s 天気変更: %s → %s (%s)", slot, c.beforeWeather, c.afterWeather, c.after)
+	default:
+		line := fmt.Sprintf("• %s 変更: %s → %s", slot, c.before, c.after)
+		if c.beforeWeather != c.afterWeather {
+			line += fmt.Sprintf("（天気: %s → %s）", c.beforeWeather, c.afterWeather)
+		}
+		return line
+	}
+}
+
+// buildDigestBlocks 見出しと行リストからブロックを作る（長い場合はsectionを分割）
+func buildDigestBlocks(header string, lines []string) []slack.Block {
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject("plain_text", header, false, false)),
+	}
+
+	var current strings.Builder
+	flush := func() {
+		if current.Len() == 0 {
+			return
+		}
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", current.String(), false, false), nil, nil))
+		current.Reset()
+	}
+
+	for _, line := range lines {
+		if current.Len()+len(line)+1 > maxDigestSectionLength {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n")
+		}
+		current.WriteString(line)
+	}
+	flush()
+
+	return blocks
+}
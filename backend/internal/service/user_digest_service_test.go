@@ -0,0 +1,81 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"seeft-slack-notification/internal/model"
+)
+
+func TestNetDigestChanges(t *testing.T) {
+	// ユーザー1の1回分のまとめ期間の変更履歴（古い順、いくつかの枠への変更が混ざっている）
+	entry := func(id int, date string, timeID int, weather, actionType, diff string) *model.ActionLogEntry {
+		return &model.ActionLogEntry{
+			ActionLog: model.ActionLog{ID: id, ActionType: actionType, DiffPayload: []byte(diff)},
+			YearID:    1,
+			Date:      date,
+			TimeID:    timeID,
+			Weather:   weather,
+			UserID:    1,
+		}
+	}
+	entries := []*model.ActionLogEntry{
+		entry(1, "1日目", 33, "晴れ", "UPDATE", `{"changes":[{"field":"task_name","old":"救護","new":"受付"}]}`),
+		entry(2, "1日目", 34, "晴れ", "CREATE", `{"new_task":"救護"}`),
+		entry(3, "1日目", 35, "雨", "UPDATE", `{"changes":[{"field":"task_name","old":"救護","new":"救護"},{"field":"weather","old":"晴れ","new":"雨"}]}`),
+		entry(4, "1日目", 33, "晴れ", "UPDATE", `{"changes":[{"field":"task_name","old":"受付","new":"救護"}]}`),
+		entry(5, "2日目", 33, "雨", "UPDATE", `{"changes":[{"field":"task_name","old":"警備","new":"受付"},{"field":"weather","old":"晴れ","new":"雨"}]}`),
+		entry(6, "1日目", 34, "晴れ", "DELETE", `{"deleted_task":"救護"}`),
+		entry(7, "1日目", 36, "晴れ", "DELETE", `{"deleted_task":"本部"}`),
+		entry(8, "1日目", 37, "晴れ", "UPDATE", `{"changes":[{"field":"task_name","old":"救護","new":"受付"}]}`),
+		entry(9, "1日目", 38, "雨", "UPDATE", `{"changes":[{"field":"task_name","old":"受付","new":"受付"},{"field":"weather","old":"晴れ","new":"雨"}]}`),
+		entry(10, "1日目", 37, "雨", "UPDATE", `{"changes":[{"field":"task_name","old":"受付","new":"救護"},{"field":"weather","old":"晴れ","new":"雨"}]}`),
+		entry(11, "1日目", 38, "晴れ", "UPDATE", `{"changes":[{"field":"task_name","old":"受付","new":"受付"},{"field":"weather","old":"雨","new":"晴れ"}]}`),
+		entry(12, "1日目", 39, "晴れ", "CREATE", `{"new_task":"救護"}`),
+		entry(13, "1日目", 39, "晴れ", "UPDATE", `{"changes":[{"field":"task_name","old":"救護","new":"受付"}]}`),
+	}
+
+	changes := netDigestChanges(entries)
+
+	want := []*digestChange{
+		// A→B→A
+		{entry: entries[0], before: "救護", after: "救護", actionLogIDs: []int{1, 4}},
+		// 追加→削除
+		{entry: entries[1], before: "", after: "", actionLogIDs: []int{2, 6}},
+		// 天気だけの変更
+		{entry: entries[2], before: "救護", after: "救護", beforeWeather: "晴れ", afterWeather: "雨", actionLogIDs: []int{3}},
+		// 別の日の同じ時間帯は別の枠
+		{entry: entries[4], before: "警備", after: "受付", beforeWeather: "晴れ", afterWeather: "雨", actionLogIDs: []int{5}},
+		{entry: entries[6], before: "本部", after: "", actionLogIDs: []int{7}},
+		// A→B→A で天気が変わった
+		{entry: entries[7], before: "救護", after: "救護", beforeWeather: "晴れ", afterWeather: "雨", actionLogIDs: []int{8, 10}},
+		// 天気が変わって元に戻った
+		{entry: entries[8], before: "受付", after: "受付", beforeWeather: "晴れ", afterWeather: "晴れ", actionLogIDs: []int{9, 11}},
+		// 追加→変更
+		{entry: entries[11], before: "", after: "受付", actionLogIDs: []int{12, 13}},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		for i, c := range changes {
+			t.Logf("changes[%d] = %+v", i, *c)
+		}
+		t.Fatalf("netDigestChanges returned %d changes, want %+v", len(changes), want)
+	}
+
+	// まとめに載る行（元に戻ったものは載らない）
+	var lines []string
+	for _, c := range changes {
+		if c.changed() {
+			lines = append(lines, c.line())
+		}
+	}
+	wantLines := []string{
+		"• 1日目 11:00 (雨) 天気変更: 晴れ → 雨 (救護)",
+		"• 2日目 10:00 (雨) 変更: 警備 → 受付（天気: 晴れ → 雨）",
+		"• 1日目 11:30 (晴れ) 削除: 本部",
+		"• 1日目 12:00 (雨) 天気変更: 晴れ → 雨 (救護)",
+		"• 1日目 13:00 (晴れ) 追加: 受付",
+	}
+	if !reflect.DeepEqual(lines, wantLines) {
+		t.Errorf("lines =\n%q\nwant\n%q", lines, wantLines)
+	}
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"seeft-slack-notification/internal/model"
+	"seeft-slack-notification/internal/repository"
+)
+
+// UserService ユーザーの管理（管理者向け）
+type UserService struct {
+	db       *sql.DB
+	userRepo *repository.UserRepository
+}
+
+func NewUserService(db *sql.DB, userRepo *repository.UserRepository) *UserService {
+	return &UserService{
+		db:       db,
+		userRepo: userRepo,
+	}
+}
+
+// List ユーザーの一覧（利用停止したユーザーは filter.IncludeInactive のときだけ含める）
+func (s *UserService) List(filter model.UserFilter) ([]*model.User, error) {
+	return s.userRepo.List(filter)
+}
+
+// Create ユーザーを追加する（権限は member）
+// 名前・SlackのユーザーID・メールアドレスは他のユーザー（改名前の名前を含む）と重ならないこと
+func (s *UserService) Create(req model.CreateUserRequest) (*model.User, error) {
+	user := &model.User{
+		Name:        strings.TrimSpace(req.Name),
+		SlackUserID: strings.TrimSpace(req.SlackUserID),
+		Email:       strings.TrimSpace(req.Email),
+		Role:        model.RoleMember,
+		Group:       strings.TrimSpace(req.Group),
+		Locale:      strings.TrimSpace(req.Locale),
+	}
+	if user.Locale == "" {
+		user.Locale = model.LocaleJa
+	}
+	if err := validateUser(user); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.checkUnique(tx, user); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.Create(tx, user); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s.userRepo.GetByID(user.ID)
+}
+
+// Update 送られた項目だけを変更し、変更後のユーザーと変わった項目を返す
+// 改名した場合は前の名前を残し、シートが古い名前のままでも同期で同じユーザーのシフトとして扱う
+func (s *UserService) Update(userID int, req model.UpdateUserRequest) (*model.User, []model.UserFieldChange, error) {
+	current, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	updated := *current
+	changes := make([]model.UserFieldChange, 0)
+	apply := func(field string, dst *string, src *string) {
+		if src == nil {
+			return
+		}
+		v := strings.TrimSpace(*src)
+		if v != *dst {
+			changes = append(changes, model.UserFieldChange{Field: field, Old: *dst, New: v})
+			*dst = v
+		}
+	}
+	apply("name", &updated.Name, req.Name)
+	apply("slack_user_id", &updated.SlackUserID, req.SlackUserID)
+	apply("email", &updated.Email, req.Email)
+	apply("locale", &updated.Locale, req.Locale)
+	apply("group", &updated.Group, req.Group)
+
+	if len(changes) == 0 {
+		return current, changes, nil
+	}
+	if err := validateUser(&updated); err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.checkUnique(tx, &updated); err != nil {
+		return nil, nil, err
+	}
+	if err := s.userRepo.Update(tx, &updated); err != nil {
+		return nil, nil, err
+	}
+	if updated.Name != current.Name {
+		if err := s.userRepo.RecordRename(tx, userID, current.Name, updated.Name); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, changes, nil
+}
+
+// Deactivate ユーザーを利用停止にする（ログインできなくなる。シフトと履歴は残る）
+// 管理者が誰もいなくならないよう、自分自身は停止できない
+func (s *UserService) Deactivate(actor *model.Principal, userID int) (*model.User, error) {
+	if actor.UserID == userID {
+		return nil, fmt.Errorf("%w: cannot deactivate yourself", ErrForbidden)
+	}
+	if err := s.userRepo.Deactivate(userID); err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetByID(userID)
+}
+
+// UpdateRole ユーザーの権限を変更し、変更後のユーザーを返す
+// 管理者が誰もいなくならないよう、自分自身の権限は変更できない
+func (s *UserService) UpdateRole(actor *model.Principal, userID int, role string) (*model.User, error) {
+	if !model.ValidRole(role) {
+		return nil, fmt.Errorf("%w: role must be one of %s, %s, %s", ErrInvalidInput, model.RoleAdmin, model.RoleLead, model.RoleMember)
+	}
+	if actor.UserID == userID {
+		return nil, fmt.Errorf("%w: cannot change your own role", ErrForbidden)
+	}
+	if err := s.userRepo.UpdateRole(userID, role); err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetByID(userID)
+}
+
+// checkUnique 名前が他のユーザーの改名前の名前と、メールアドレスが他のユーザーと重なっていないか
+// （名前・SlackのユーザーIDそのものの重複は一意制約で検出する）
+func (s *UserService) checkUnique(tx *sql.Tx, user *model.User) error {
+	owner, err := s.userRepo.GetAliasOwner(tx, user.Name)
+	if err != nil {
+		return err
+	}
+	if owner != 0 && owner != user.ID {
+		return fmt.Errorf("user name %w: %s is a former name of user %d", ErrConflict, user.Name, owner)
+	}
+
+	if user.Email != "" {
+		taken, err := s.userRepo.EmailTaken(tx, user.Email, user.ID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return fmt.Errorf("email %w: %s", ErrConflict, user.Email)
+		}
+	}
+	return nil
+}
+
+// validateUser 追加・更新するユーザーの値を確認する
+func validateUser(user *model.User) error {
+	if user.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if user.SlackUserID == "" {
+		return fmt.Errorf("%w: slack_user_id is required", ErrInvalidInput)
+	}
+	if len(user.Name) > 255 || len(user.SlackUserID) > 255 || len(user.Email) > 255 {
+		return fmt.Errorf("%w: name, slack_user_id and email must not exceed 255 bytes", ErrInvalidInput)
+	}
+	if len(user.Group) > 100 {
+		return fmt.Errorf("%w: group must not exceed 100 bytes", ErrInvalidInput)
+	}
+	if user.Email != "" {
+		if addr, err := mail.ParseAddress(user.Email); err != nil || addr.Address != user.Email {
+			return fmt.Errorf("%w: invalid email", ErrInvalidInput)
+		}
+	}
+	if !model.ValidLocale(user.Locale) {
+		return fmt.Errorf("%w: locale must be one of %s, %s", ErrInvalidInput, model.LocaleJa, model.LocaleEn)
+	}
+	return nil
+}
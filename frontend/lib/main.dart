@@ -1,6 +1,7 @@
 import 'package:flutter/material.dart';
 import 'screens/notification_list_screen.dart';
 import 'services/api_service.dart';
+import 'services/auth_session.dart';
 
 void main() {
   runApp(const MyApp());
@@ -30,19 +31,45 @@ class HomeScreen extends StatefulWidget {
 }
 
 class _HomeScreenState extends State<HomeScreen> {
-  final _userIdController = TextEditingController();
   final _apiService = ApiService();
+  bool _isLoading = true;
 
-  void _navigateToNotifications() {
-    final userId = int.tryParse(_userIdController.text);
-    if (userId == null) {
-      ScaffoldMessenger.of(context).showSnackBar(
-        const SnackBar(content: Text('有効なユーザーIDを入力してください')),
-      );
+  @override
+  void initState() {
+    super.initState();
+    _restoreSession();
+  }
+
+  // ログイン後のリダイレクトや保存済みのトークンがあれば、そのまま通知一覧へ進む
+  Future<void> _restoreSession() async {
+    final token = loadToken();
+    if (token == null) {
+      setState(() => _isLoading = false);
       return;
     }
 
-    Navigator.push(
+    _apiService.token = token;
+    try {
+      final userId = await _apiService.getMyUserId();
+      if (userId == null) {
+        // 期限切れ
+        clearToken();
+        _apiService.token = null;
+      } else if (mounted) {
+        _navigateToNotifications(userId);
+      }
+    } catch (e) {
+      if (mounted) {
+        ScaffoldMessenger.of(context).showSnackBar(
+          SnackBar(content: Text('ログイン状態の確認に失敗しました: $e')),
+        );
+      }
+    }
+    if (mounted) setState(() => _isLoading = false);
+  }
+
+  void _navigateToNotifications(int userId) {
+    Navigator.pushReplacement(
       context,
       MaterialPageRoute(
         builder: (context) => NotificationListScreen(
@@ -59,35 +86,15 @@ class _HomeScreenState extends State<HomeScreen> {
       appBar: AppBar(
         title: const Text('シフト変更通知'),
       ),
-      body: Padding(
-        padding: const EdgeInsets.all(16.0),
-        child: Column(
-          mainAxisAlignment: MainAxisAlignment.center,
-          children: [
-            TextField(
-              controller: _userIdController,
-              decoration: const InputDecoration(
-                labelText: 'ユーザーID',
-                hintText: '例: 1',
-                border: OutlineInputBorder(),
+      body: Center(
+        child: _isLoading
+            ? const CircularProgressIndicator()
+            : ElevatedButton.icon(
+                onPressed: () => openLogin(_apiService.loginUrl),
+                icon: const Icon(Icons.login),
+                label: const Text('Slackでログイン'),
               ),
-              keyboardType: TextInputType.number,
-            ),
-            const SizedBox(height: 16),
-            ElevatedButton(
-              onPressed: _navigateToNotifications,
-              child: const Text('通知一覧を表示'),
-            ),
-          ],
-        ),
       ),
     );
   }
-
-  @override
-  void dispose() {
-    _userIdController.dispose();
-    super.dispose();
-  }
 }
-
@@ -0,0 +1,23 @@
+import 'shift_notification.dart';
+
+// SSE (/api/events) で届くイベント
+// change: 新しい変更 / unread_count: 未読件数の更新
+class ChangeEvent {
+  final ShiftNotification? notification;
+  final int? unreadCount;
+
+  ChangeEvent.change(ShiftNotification this.notification) : unreadCount = null;
+  ChangeEvent.unreadCount(int this.unreadCount) : notification = null;
+
+  // event名とdata(JSON)から作る。知らないイベントはnull
+  static ChangeEvent? fromSse(String event, Map<String, dynamic> data) {
+    switch (event) {
+      case 'change':
+        return ChangeEvent.change(ShiftNotification.fromJson(data));
+      case 'unread_count':
+        return ChangeEvent.unreadCount(data['unread_count'] as int);
+      default:
+        return null;
+    }
+  }
+}
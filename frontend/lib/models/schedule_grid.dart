@@ -0,0 +1,78 @@
+// スケジュール表（ユーザー × 時間枠）。/api/schedule/grid のレスポンス
+class ScheduleGrid {
+  final int yearId;
+  final String date;
+  final String weather;
+  final List<GridSlot> slots;
+  final List<String> tasks;
+  final List<GridUserRow> rows;
+
+  ScheduleGrid({
+    required this.yearId,
+    required this.date,
+    required this.weather,
+    required this.slots,
+    required this.tasks,
+    required this.rows,
+  });
+
+  factory ScheduleGrid.fromJson(Map<String, dynamic> json) {
+    return ScheduleGrid(
+      yearId: json['year_id'] as int,
+      date: json['date'] as String,
+      weather: json['weather'] as String,
+      slots: (json['slots'] as List)
+          .map((s) => GridSlot.fromJson(s as Map<String, dynamic>))
+          .toList(),
+      tasks: (json['tasks'] as List).cast<String>(),
+      rows: (json['rows'] as List)
+          .map((r) => GridUserRow.fromJson(r as Map<String, dynamic>))
+          .toList(),
+    );
+  }
+
+  // セルのタスク名（空きは null）
+  String? taskAt(GridUserRow row, int slotIndex) {
+    final index = row.cells[slotIndex];
+    return index == null ? null : tasks[index];
+  }
+}
+
+class GridSlot {
+  final int timeId;
+  final String start;
+  final String end;
+
+  GridSlot({required this.timeId, required this.start, required this.end});
+
+  factory GridSlot.fromJson(Map<String, dynamic> json) {
+    return GridSlot(
+      timeId: json['time_id'] as int,
+      start: json['start'] as String,
+      end: json['end'] as String,
+    );
+  }
+}
+
+class GridUserRow {
+  final int userId;
+  final String userName;
+  final String group;
+  final List<int?> cells; // tasks のインデックス
+
+  GridUserRow({
+    required this.userId,
+    required this.userName,
+    required this.group,
+    required this.cells,
+  });
+
+  factory GridUserRow.fromJson(Map<String, dynamic> json) {
+    return GridUserRow(
+      userId: json['user_id'] as int,
+      userName: json['user_name'] as String,
+      group: json['group'] as String? ?? '',
+      cells: (json['cells'] as List).cast<int?>(),
+    );
+  }
+}
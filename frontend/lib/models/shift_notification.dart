@@ -1,5 +1,6 @@
 class ShiftNotification {
   final int id;
+  final int shiftId;
   final String userName;
   final int yearId;
   final int timeId;
@@ -12,6 +13,7 @@ class ShiftNotification {
 
   ShiftNotification({
     required this.id,
+    required this.shiftId,
     required this.userName,
     required this.yearId,
     required this.timeId,
@@ -26,6 +28,7 @@ class ShiftNotification {
   factory ShiftNotification.fromJson(Map<String, dynamic> json) {
     return ShiftNotification(
       id: json['id'] as int,
+      shiftId: json['shift_id'] as int,
       userName: json['user_name'] as String,
       yearId: json['year_id'] as int,
       timeId: json['time_id'] as int,
@@ -41,6 +44,7 @@ class ShiftNotification {
   Map<String, dynamic> toJson() {
     return {
       'id': id,
+      'shift_id': shiftId,
       'user_name': userName,
       'year_id': yearId,
       'time_id': timeId,
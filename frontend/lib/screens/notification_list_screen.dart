@@ -1,4 +1,7 @@
+import 'dart:async';
+
 import 'package:flutter/material.dart';
+import '../models/change_event.dart';
 import '../models/shift_notification.dart';
 import '../services/api_service.dart';
 
@@ -19,11 +22,35 @@ class NotificationListScreen extends StatefulWidget {
 class _NotificationListScreenState extends State<NotificationListScreen> {
   List<ShiftNotification> _notifications = [];
   bool _isLoading = true;
+  int _unreadCount = 0;
+  StreamSubscription<ChangeEvent>? _changes;
 
   @override
   void initState() {
     super.initState();
     _loadNotifications();
+    // 開いている間は新しい変更をリアルタイムで受け取る
+    _changes = widget.apiService.changes().listen(_onChange);
+  }
+
+  @override
+  void dispose() {
+    _changes?.cancel();
+    super.dispose();
+  }
+
+  void _onChange(ChangeEvent event) {
+    if (!mounted) return;
+    setState(() {
+      final notification = event.notification;
+      if (notification != null &&
+          !_notifications.any((n) => n.id == notification.id)) {
+        _notifications.insert(0, notification);
+      }
+      if (event.unreadCount != null) {
+        _unreadCount = event.unreadCount!;
+      }
+    });
   }
 
   Future<void> _loadNotifications() async {
@@ -33,8 +60,10 @@ class _NotificationListScreenState extends State<NotificationListScreen> {
 
     try {
       final notifications = await widget.apiService.getNotifications(widget.userId);
+      final unreadCount = await widget.apiService.getUnreadCount(widget.userId);
       setState(() {
         _notifications = notifications;
+        _unreadCount = unreadCount;
         _isLoading = false;
       });
     } catch (e) {
@@ -56,6 +85,7 @@ Future<void> _openNotificationDetail(ShiftNotification notification) async {
       if (index != -1) {
         _notifications[index] = ShiftNotification(
           id: notification.id,
+          shiftId: notification.shiftId,
           userName: notification.userName,
           yearId: notification.yearId,
           timeId: notification.timeId,
@@ -82,7 +112,13 @@ Future<void> _openNotificationDetail(ShiftNotification notification) async {
     // 3. 【バックグラウンド実行】裏側でAPIを呼び出す
     try {
       // ここで await しても、既に画面遷移とUI更新は終わっているのでユーザーを待たせない
-      await widget.apiService.markAsRead(notification.id, widget.userId);
+      await widget.apiService.markAsRead(notification.shiftId);
+      final unreadCount = await widget.apiService.getUnreadCount(widget.userId);
+      if (mounted) {
+        setState(() {
+          _unreadCount = unreadCount;
+        });
+      }
     } catch (e) {
       // 4. 【ロールバック】APIが失敗した場合は未読に戻す
       if (mounted) {
@@ -91,6 +127,7 @@ Future<void> _openNotificationDetail(ShiftNotification notification) async {
           if (index != -1) {
             _notifications[index] = ShiftNotification(
               id: notification.id,
+              shiftId: notification.shiftId,
               userName: notification.userName,
               yearId: notification.yearId,
               timeId: notification.timeId,
@@ -124,7 +161,7 @@ Future<void> _openNotificationDetail(ShiftNotification notification) async {
   Widget build(BuildContext context) {
     return Scaffold(
       appBar: AppBar(
-        title: const Text('シフト変更通知'),
+        title: Text(_unreadCount > 0 ? 'シフト変更通知 (未読 $_unreadCount)' : 'シフト変更通知'),
         actions: [
           IconButton(
             icon: const Icon(Icons.refresh),
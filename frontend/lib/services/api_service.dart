@@ -1,10 +1,19 @@
 import 'dart:convert';
 import 'package:http/http.dart' as http;
+import '../models/change_event.dart';
+import '../models/schedule_grid.dart';
 import '../models/shift_notification.dart';
+import 'change_stream.dart';
 
 class ApiService {
   final String baseUrl;
 
+  // ログインで発行されたAPIトークン（全APIに Authorization: Bearer で付ける）
+  String? token;
+
+  // スケジュール表のキャッシュ（URL -> ETag と内容）。変わっていなければ 304 で再利用する
+  final Map<String, (String, ScheduleGrid)> _gridCache = {};
+
   // 環境変数から読み込むか、デフォルト値を使用
   // Docker環境: http://go:8080
   // ローカル環境: http://localhost:8080
@@ -25,36 +34,124 @@ class ApiService {
     return 'http://localhost:8080';
   }
 
-  // 未読通知一覧を取得
- Future<List<ShiftNotification>> getNotifications(int userId) async {
-  try {
+  Map<String, String> get _headers => {
+        if (token != null) 'Authorization': 'Bearer $token',
+      };
+
+  // Slackでログインする画面のURL
+  String get loginUrl => '$baseUrl/api/auth/slack/login';
+
+  // ログイン中のユーザーIDを取得（トークンが無効ならnull）
+  Future<int?> getMyUserId() async {
     final response = await http.get(
-      Uri.parse('$baseUrl/api/notifications?user_id=$userId'),
-    ).timeout(const Duration(seconds: 10)); // タイムアウト追加
-
-    if (response.statusCode == 200) {
-      final data = json.decode(response.body);
-      final notificationsJson = data['notifications'] as List?; // null安全
-      
-      if (notificationsJson == null) return [];
-      
-      return notificationsJson
-          .map((json) => ShiftNotification.fromJson(json))
-          .toList();
-    } else {
-      // サーバーからのエラーメッセージを含めるとデバッグしやすい
+      Uri.parse('$baseUrl/api/auth/me'),
+      headers: _headers,
+    ).timeout(const Duration(seconds: 10));
+
+    if (response.statusCode == 401) return null;
+    if (response.statusCode != 200) {
       throw Exception('ステータスコード: ${response.statusCode}');
     }
-  } catch (e) {
-    throw Exception('通信エラー: $e');
+    return json.decode(response.body)['user_id'] as int;
+  }
+
+  // 通知一覧（自分の全シフトの変更タイムライン、削除も含む）を取得
+  // cursor を渡すと続きのページを取得する
+  Future<TimelinePage> getTimeline(int userId, {String? cursor, int limit = 50}) async {
+    try {
+      final query = {
+        'limit': '$limit',
+        if (cursor != null) 'cursor': cursor,
+      };
+      final response = await http.get(
+        Uri.parse('$baseUrl/api/users/$userId/timeline').replace(queryParameters: query),
+        headers: _headers,
+      ).timeout(const Duration(seconds: 10)); // タイムアウト追加
+
+      if (response.statusCode == 200) {
+        final data = json.decode(response.body);
+        final entriesJson = data['entries'] as List? ?? []; // null安全
+
+        return TimelinePage(
+          notifications: entriesJson
+              .map((json) => ShiftNotification.fromJson(json))
+              .toList(),
+          nextCursor: data['next_cursor'] as String?,
+        );
+      } else {
+        // サーバーからのエラーメッセージを含めるとデバッグしやすい
+        throw Exception('ステータスコード: ${response.statusCode}');
+      }
+    } catch (e) {
+      throw Exception('通信エラー: $e');
+    }
+  }
+
+  // 通知一覧の先頭ページを取得
+  Future<List<ShiftNotification>> getNotifications(int userId) async {
+    final page = await getTimeline(userId);
+    return page.notifications;
+  }
+
+  // 未読件数を取得
+  Future<int> getUnreadCount(int userId) async {
+    final response = await http.get(
+      Uri.parse('$baseUrl/api/users/$userId/unread_count'),
+      headers: _headers,
+    ).timeout(const Duration(seconds: 10));
+
+    if (response.statusCode != 200) {
+      throw Exception('ステータスコード: ${response.statusCode}');
+    }
+    return json.decode(response.body)['unread_count'] as int;
+  }
+
+  // スケジュール表を取得（task / group で絞り込み）
+  Future<ScheduleGrid> getScheduleGrid({
+    required int yearId,
+    required String date,
+    required String weather,
+    String? task,
+    String? group,
+  }) async {
+    final uri = Uri.parse('$baseUrl/api/schedule/grid').replace(queryParameters: {
+      'year_id': '$yearId',
+      'date': date,
+      'weather': weather,
+      if (task != null) 'task': task,
+      if (group != null) 'group': group,
+    });
+    final cached = _gridCache[uri.toString()];
+
+    final response = await http.get(uri, headers: {
+      ..._headers,
+      if (cached != null) 'If-None-Match': cached.$1,
+    }).timeout(const Duration(seconds: 10));
+
+    if (response.statusCode == 304 && cached != null) {
+      return cached.$2;
+    }
+    if (response.statusCode != 200) {
+      throw Exception('ステータスコード: ${response.statusCode}');
+    }
+
+    final grid = ScheduleGrid.fromJson(json.decode(response.body));
+    final etag = response.headers['etag'];
+    if (etag != null) _gridCache[uri.toString()] = (etag, grid);
+    return grid;
   }
-}
 
-  // 通知を既読にする
-  Future<void> markAsRead(int notificationId, int userId) async {
+  // 新しい変更と未読件数をリアルタイムで受け取る（listen をやめると切断する）
+  Stream<ChangeEvent> changes() {
+    return connectChangeStream(baseUrl, token ?? '');
+  }
+
+  // 通知のシフトを既読にする
+  Future<void> markAsRead(int shiftId) async {
     try {
       final response = await http.post(
-        Uri.parse('$baseUrl/api/notifications/$notificationId/read?user_id=$userId'),
+        Uri.parse('$baseUrl/api/shifts/$shiftId/read'),
+        headers: _headers,
       );
 
       if (response.statusCode != 200) {
@@ -64,4 +161,25 @@ class ApiService {
       throw Exception('Error: $e');
     }
   }
+
+  // カレンダーアプリで購読するURLを発行する（再発行すると以前のURLは使えなくなる）
+  Future<String> issueCalendarUrl() async {
+    final response = await http.post(
+      Uri.parse('$baseUrl/api/users/me/calendar_token'),
+      headers: _headers,
+    ).timeout(const Duration(seconds: 10));
+
+    if (response.statusCode != 200) {
+      throw Exception('ステータスコード: ${response.statusCode}');
+    }
+    return json.decode(response.body)['url'] as String;
+  }
+}
+
+// タイムラインの1ページ分
+class TimelinePage {
+  final List<ShiftNotification> notifications;
+  final String? nextCursor; // 次のページが無ければnull
+
+  TimelinePage({required this.notifications, this.nextCursor});
 }
@@ -0,0 +1,3 @@
+// ログイン（Sign in with Slack）で受け取ったトークンの保存とログイン画面への遷移
+// Webはブラウザの localStorage とリダイレクト、それ以外はビルド時の API_TOKEN を使う
+export 'auth_session_io.dart' if (dart.library.html) 'auth_session_web.dart';
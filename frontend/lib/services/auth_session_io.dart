@@ -0,0 +1,12 @@
+// Web以外ではブラウザのリダイレクトが使えないので、発行済みのトークンを
+// --dart-define=API_TOKEN=... で渡す
+String? loadToken() {
+  const token = String.fromEnvironment('API_TOKEN');
+  return token.isEmpty ? null : token;
+}
+
+void clearToken() {}
+
+void openLogin(String loginUrl) {
+  throw UnsupportedError('Slackログインは Web 版のみ対応しています');
+}
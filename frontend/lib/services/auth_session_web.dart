@@ -0,0 +1,28 @@
+import 'dart:html' as html;
+
+const _storageKey = 'seeft_auth_token';
+
+// ログイン後のリダイレクト(#token=...)からトークンを取り出して保存する
+// フラグメントが無ければ前回保存したトークンを返す
+String? loadToken() {
+  final hash = html.window.location.hash;
+  if (hash.length > 1) {
+    final token = Uri.splitQueryString(hash.substring(1))['token'];
+    if (token != null && token.isNotEmpty) {
+      html.window.localStorage[_storageKey] = token;
+      // トークンをURLに残さない
+      html.window.history.replaceState(null, '', html.window.location.pathname);
+      return token;
+    }
+  }
+  return html.window.localStorage[_storageKey];
+}
+
+void clearToken() {
+  html.window.localStorage.remove(_storageKey);
+}
+
+// バックエンドのログインURLへ移動する（Slackでログイン後、このアプリに戻ってくる）
+void openLogin(String loginUrl) {
+  html.window.location.assign(loginUrl);
+}
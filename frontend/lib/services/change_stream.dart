@@ -0,0 +1,3 @@
+// 変更のリアルタイム受信（Server-Sent Events）
+// Webはブラウザの EventSource、それ以外は http のストリームで受け取る
+export 'change_stream_io.dart' if (dart.library.html) 'change_stream_web.dart';
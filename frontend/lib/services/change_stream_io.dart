@@ -0,0 +1,74 @@
+import 'dart:async';
+import 'dart:convert';
+
+import 'package:http/http.dart' as http;
+
+import '../models/change_event.dart';
+
+// 切断されたら少し待って、最後に受け取ったIDから再開する
+const _retryDelay = Duration(seconds: 3);
+
+Stream<ChangeEvent> connectChangeStream(String baseUrl, String token) {
+  late StreamController<ChangeEvent> controller;
+  http.Client? client;
+  String? lastEventId;
+  var cancelled = false;
+
+  Future<void> run() async {
+    while (!cancelled) {
+      client = http.Client();
+      try {
+        final request = http.Request(
+          'GET',
+          Uri.parse('$baseUrl/api/events'),
+        );
+        request.headers['Accept'] = 'text/event-stream';
+        request.headers['Authorization'] = 'Bearer $token';
+        if (lastEventId != null) request.headers['Last-Event-ID'] = lastEventId!;
+
+        final response = await client!.send(request);
+        var event = 'message';
+        var data = StringBuffer();
+        String? id;
+
+        await for (final line in response.stream
+            .transform(utf8.decoder)
+            .transform(const LineSplitter())) {
+          if (line.isEmpty) {
+            // 空行でイベントが確定する
+            if (data.isNotEmpty) {
+              final changeEvent = ChangeEvent.fromSse(event, json.decode(data.toString()));
+              if (changeEvent != null) controller.add(changeEvent);
+            }
+            if (id != null) lastEventId = id;
+            event = 'message';
+            data = StringBuffer();
+            id = null;
+          } else if (line.startsWith('event:')) {
+            event = line.substring(6).trim();
+          } else if (line.startsWith('data:')) {
+            data.write(line.substring(5).trim());
+          } else if (line.startsWith('id:')) {
+            id = line.substring(3).trim();
+          }
+          // ':' で始まる行はハートビートなので無視
+        }
+      } catch (_) {
+        // 再接続する
+      } finally {
+        client?.close();
+      }
+
+      if (!cancelled) await Future.delayed(_retryDelay);
+    }
+  }
+
+  controller = StreamController<ChangeEvent>(
+    onListen: run,
+    onCancel: () {
+      cancelled = true;
+      client?.close();
+    },
+  );
+  return controller.stream;
+}
@@ -0,0 +1,32 @@
+import 'dart:async';
+import 'dart:convert';
+import 'dart:html' as html;
+
+import '../models/change_event.dart';
+
+// EventSource は切断時の再接続と Last-Event-ID の送信を自動で行う
+// ヘッダーを付けられないので、トークンはクエリ(access_token)で渡す
+Stream<ChangeEvent> connectChangeStream(String baseUrl, String token) {
+  late html.EventSource source;
+  late StreamController<ChangeEvent> controller;
+
+  void listen(String event) {
+    source.addEventListener(event, (e) {
+      final data = (e as html.MessageEvent).data as String;
+      final changeEvent = ChangeEvent.fromSse(event, json.decode(data));
+      if (changeEvent != null) controller.add(changeEvent);
+    });
+  }
+
+  controller = StreamController<ChangeEvent>(
+    onListen: () {
+      final url = Uri.parse('$baseUrl/api/events')
+          .replace(queryParameters: {'access_token': token});
+      source = html.EventSource(url.toString());
+      listen('change');
+      listen('unread_count');
+    },
+    onCancel: () => source.close(),
+  );
+  return controller.stream;
+}